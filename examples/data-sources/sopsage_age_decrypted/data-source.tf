@@ -0,0 +1,4 @@
+data "sopsage_age_decrypted" "example" {
+  encrypted        = file("${path.module}/tls.key.age")
+  age_private_keys = [var.age_private_key]
+}
@@ -0,0 +1,3 @@
+data "sopsage_public_key_from_age_private_key" "example" {
+  age_private_key = var.age_private_key
+}
@@ -0,0 +1,4 @@
+ephemeral "sopsage_decrypted_file" "example" {
+  filename         = "${path.module}/secrets/database.enc.yaml"
+  age_private_keys = [var.age_private_key]
+}
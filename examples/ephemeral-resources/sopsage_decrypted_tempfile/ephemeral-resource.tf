@@ -0,0 +1,11 @@
+ephemeral "sopsage_decrypted_tempfile" "values" {
+  filename = "${path.module}/secrets/values.enc.yaml"
+}
+
+resource "terraform_data" "helm_upgrade" {
+  triggers_replace = [filesha256("${path.module}/secrets/values.enc.yaml")]
+
+  provisioner "local-exec" {
+    command = "helm upgrade --install app ./chart --values ${ephemeral.sopsage_decrypted_tempfile.values.path}"
+  }
+}
@@ -0,0 +1,7 @@
+output "encrypted" {
+  value = provider::sopsage::age_encrypt(
+    "secret",
+    ["age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"],
+    true,
+  )
+}
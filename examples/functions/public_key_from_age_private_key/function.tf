@@ -0,0 +1,7 @@
+# Encrypt for the identity kept in a secret manager without storing its
+# recipient next to it.
+resource "sopsage_encrypted_data" "example" {
+  format          = "yaml"
+  content         = yamlencode({ password = "secret" })
+  age_public_keys = [provider::sopsage::public_key_from_age_private_key(var.age_private_key)]
+}
@@ -0,0 +1,5 @@
+# Re-emit a SOPS YAML file as SOPS JSON for a tool that only reads JSON. No
+# identity is needed, and the JSON document decrypts to the same values.
+output "secrets_json" {
+  value = provider::sopsage::sops_convert(file("${path.module}/secrets.enc.yaml"), "yaml", "json")
+}
@@ -0,0 +1,22 @@
+# The seed must stay secret: anyone knowing it can derive the data key of the
+# encrypted documents. Generate it once and keep it in a secret store.
+variable "sops_seed" {
+  type      = string
+  sensitive = true
+}
+
+locals {
+  # The same content and seed always give the same document, so committing it
+  # to git only produces a diff when the content changes.
+  encrypted_config = provider::sopsage::sops_encrypt_deterministic(
+    yamlencode({ password = "secret" }),
+    "yaml",
+    ["age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"],
+    var.sops_seed,
+    false, # mac_only_encrypted
+  )
+}
+
+output "encrypted_config" {
+  value = nonsensitive(local.encrypted_config)
+}
@@ -1 +1,28 @@
 provider "sopsage" {}
+
+# Delegate data key encryption and decryption to a sops keyservice running
+# next to the HSM, so that no private identity enters the Terraform process.
+provider "sopsage" {
+  alias                   = "hsm"
+  keyservices             = ["unix:///run/sops/keyservice.sock"]
+  enable_local_keyservice = false
+}
+
+# Decrypt with the identities of age key files when no age_private_keys are
+# given inline. Without age_key_file or age_key_files,
+# ~/.config/sops/age/keys.txt is read like the sops CLI does. The identities of
+# SOPS_AGE_KEY, SOPS_AGE_KEY_FILE and SOPS_AGE_SSH_PRIVATE_KEY_FILE are added
+# unless environment_identities is "ignore".
+provider "sopsage" {
+  alias                  = "keys"
+  age_key_file           = "/run/secrets/age-keys.txt"
+  environment_identities = "ignore"
+}
+
+# Format the encrypted documents like the prettier and yamllint settings of
+# the repository they are committed to.
+provider "sopsage" {
+  alias       = "formatted"
+  yaml_indent = 2
+  json_indent = 2
+}
@@ -0,0 +1,6 @@
+# Escrow copy encrypted to a passphrase kept offline. age does not allow a
+# passphrase to be combined with public keys, so this is a separate resource.
+resource "sopsage_age_encrypted" "escrow" {
+  content    = file("${path.module}/tls.key")
+  passphrase = var.escrow_passphrase
+}
@@ -0,0 +1,4 @@
+resource "sopsage_age_encrypted" "example" {
+  content         = file("${path.module}/tls.key")
+  age_public_keys = ["age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"]
+}
@@ -0,0 +1,18 @@
+# Only the password is encrypted, the plan fails if a change to the
+# content or to encrypted_regex would leave it in cleartext.
+resource "sopsage_encrypted_data" "database" {
+  format = "yaml"
+  content = yamlencode({
+    host     = "db.example.com"
+    password = "secret"
+  })
+  encrypted_regex         = "^password$"
+  require_encrypted_paths = ["[\"password\"]"]
+  age_public_keys = [
+    "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+  ]
+}
+
+output "cleartext_paths" {
+  value = sopsage_encrypted_data.database.cleartext_paths
+}
@@ -0,0 +1,15 @@
+resource "sopsage_encrypted_data" "bundle" {
+  format = "yaml"
+  content = join("---\n", [
+    for name, password in var.database_passwords : yamlencode({
+      apiVersion = "v1"
+      kind       = "Secret"
+      metadata   = { name = name }
+      stringData = { password = password }
+    })
+  ])
+  encrypted_regex = "^(data|stringData)$"
+  age_public_keys = [
+    "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+  ]
+}
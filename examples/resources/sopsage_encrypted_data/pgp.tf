@@ -0,0 +1,8 @@
+# During a PGP to age migration, the same document can be decrypted with
+# either the age private key or the PGP private key.
+resource "sopsage_encrypted_data" "pgp" {
+  format          = "yaml"
+  content         = yamlencode({ password = var.password })
+  age_public_keys = ["age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"]
+  pgp_public_keys = [file("${path.module}/ops-team.asc")]
+}
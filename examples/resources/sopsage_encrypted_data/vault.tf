@@ -0,0 +1,20 @@
+provider "sopsage" {
+  vault = {
+    address = "https://vault.example.com:8200"
+    approle = {
+      role_id   = var.vault_role_id
+      secret_id = var.vault_secret_id
+    }
+  }
+}
+
+# Decryptable with the age private key or through the Vault transit key.
+resource "sopsage_encrypted_data" "vault" {
+  format          = "yaml"
+  content         = yamlencode({ password = var.password })
+  age_public_keys = ["age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"]
+  vault_transit_keys = [{
+    address  = "https://vault.example.com:8200"
+    key_name = "sops"
+  }]
+}
@@ -0,0 +1,8 @@
+resource "sopsage_encrypted_file" "example" {
+  filename = "${path.module}/secrets/database.enc.yaml"
+  format   = "yaml"
+  content  = yamlencode({ password = var.database_password })
+  age_public_keys = [
+    "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+  ]
+}
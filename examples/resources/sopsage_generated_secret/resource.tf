@@ -0,0 +1,20 @@
+resource "sopsage_generated_secret" "database" {
+  format = "yaml"
+  age_public_keys = [
+    "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+  ]
+  secrets = {
+    password = {
+      length = 24
+    }
+    pin = {
+      length  = 6
+      charset = "0123456789"
+    }
+    session_key = {
+      type             = "hex"
+      length           = 32
+      rotation_trigger = "2025-01"
+    }
+  }
+}
@@ -0,0 +1,13 @@
+resource "sopsage_kubernetes_secret" "example" {
+  name      = "database"
+  namespace = "default"
+  labels = {
+    "app.kubernetes.io/name" = "database"
+  }
+  data = {
+    password = var.database_password
+  }
+  age_public_keys = [
+    "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+  ]
+}
@@ -0,0 +1,8 @@
+resource "sopsage_reencrypted_data" "test" {
+  format           = "yaml"
+  source_encrypted = file("secrets.enc.yaml")
+  age_private_keys = [var.old_age_private_key]
+  age_public_keys = [
+    "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+  ]
+}
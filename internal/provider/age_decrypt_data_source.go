@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &ageDecryptDataSource{}
+	_ datasource.DataSourceWithConfigure = &ageDecryptDataSource{}
+)
+
+// NewAgeDecryptDataSource is a helper function to simplify the provider implementation.
+func NewAgeDecryptDataSource() datasource.DataSource {
+	return &ageDecryptDataSource{}
+}
+
+// ageDecryptDataSource is the data source implementation.
+type ageDecryptDataSource struct {
+	providerData *sopsAgeProviderData
+}
+
+// ageDecryptDataSourceModel maps the data source schema data.
+type ageDecryptDataSourceModel struct {
+	ID                      types.String `tfsdk:"id"`
+	Encrypted               types.String `tfsdk:"encrypted"`
+	AgePrivateKeys          types.List   `tfsdk:"age_private_keys"`
+	Passphrase              types.String `tfsdk:"passphrase"`
+	PassphraseMaxWorkFactor types.Int64  `tfsdk:"passphrase_max_work_factor"`
+	Content                 types.String `tfsdk:"content"`
+	ContentBase64           types.String `tfsdk:"content_base64"`
+}
+
+// Configure adds the provider data to the data source.
+func (d *ageDecryptDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	d.providerData = providerData
+}
+
+// Metadata returns the data source type name.
+func (d *ageDecryptDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_age_decrypted"
+}
+
+// Schema defines the schema for the data source.
+func (d *ageDecryptDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Decrypts age encrypted content. The decrypted content is stored in the Terraform state, " +
+			"use the ephemeral resource of the same name to avoid it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the data source.",
+				Computed:    true,
+			},
+			"encrypted": schema.StringAttribute{
+				Description: "The ASCII armored or base64 encoded age encrypted content.",
+				Required:    true,
+			},
+			"age_private_keys": schema.ListAttribute{
+				Description: "List of age private keys or age plugin identities to decrypt with. " +
+					"Defaults to the identities of the provider age key files when passphrase is not set.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"passphrase": schema.StringAttribute{
+				Description: "Passphrase to decrypt with, for content encrypted to an age scrypt recipient.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"passphrase_max_work_factor": schema.Int64Attribute{
+				Description: "The maximum scrypt work factor (log2 of the cost) accepted with passphrase, between 1 and 30, defaults to 22 like age.",
+				Optional:    true,
+				Validators:  []validator.Int64{int64validator.Between(1, 30)},
+			},
+			"content": schema.StringAttribute{
+				Description: "The decrypted content.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"content_base64": schema.StringAttribute{
+				Description: "The base64 encoded decrypted content, for binary content.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *ageDecryptDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state ageDecryptDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var agePrivateKeys []string
+	diags = state.AgePrivateKeys.ElementsAs(ctx, &agePrivateKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agePrivateKey := strings.Join(agePrivateKeys, "\n")
+	var passphrase *AgePassphrase
+	if !state.Passphrase.IsNull() {
+		passphrase = &AgePassphrase{
+			Passphrase: state.Passphrase.ValueString(),
+			WorkFactor: int(state.PassphraseMaxWorkFactor.ValueInt64()),
+		}
+	} else {
+		agePrivateKey = d.providerData.agePrivateKey(agePrivateKeys)
+	}
+
+	encrypted := state.Encrypted.ValueString()
+	content, err := AgeDecryptData(encrypted, agePrivateKey, passphrase)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Decrypting Content",
+			fmt.Sprintf("Could not decrypt content: %s", err),
+		)
+		return
+	}
+
+	h := sha256.New()
+	h.Write([]byte(encrypted))
+	id := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	state.ID = types.StringValue(id)
+	state.Content = types.StringValue(string(content))
+	state.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
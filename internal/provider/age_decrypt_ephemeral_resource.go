@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource              = &ageDecryptEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure = &ageDecryptEphemeralResource{}
+)
+
+// NewAgeDecryptEphemeralResource is a helper function to simplify the provider implementation.
+func NewAgeDecryptEphemeralResource() ephemeral.EphemeralResource {
+	return &ageDecryptEphemeralResource{}
+}
+
+// ageDecryptEphemeralResource is the ephemeral resource implementation.
+type ageDecryptEphemeralResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// ageDecryptEphemeralResourceModel maps the ephemeral resource schema data.
+type ageDecryptEphemeralResourceModel struct {
+	Encrypted               types.String `tfsdk:"encrypted"`
+	AgePrivateKeys          types.List   `tfsdk:"age_private_keys"`
+	Passphrase              types.String `tfsdk:"passphrase"`
+	PassphraseMaxWorkFactor types.Int64  `tfsdk:"passphrase_max_work_factor"`
+	Content                 types.String `tfsdk:"content"`
+	ContentBase64           types.String `tfsdk:"content_base64"`
+}
+
+// Configure adds the provider data to the ephemeral resource.
+func (e *ageDecryptEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	e.providerData = providerData
+}
+
+// Metadata returns the ephemeral resource type name.
+func (e *ageDecryptEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_age_decrypted"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (e *ageDecryptEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Decrypts age encrypted content without storing the decrypted content in the Terraform state.",
+		Attributes: map[string]schema.Attribute{
+			"encrypted": schema.StringAttribute{
+				Description: "The ASCII armored or base64 encoded age encrypted content.",
+				Required:    true,
+			},
+			"age_private_keys": schema.ListAttribute{
+				Description: "List of age private keys or age plugin identities to decrypt with. " +
+					"Defaults to the identities of the provider age key files when passphrase is not set.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"passphrase": schema.StringAttribute{
+				Description: "Passphrase to decrypt with, for content encrypted to an age scrypt recipient.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"passphrase_max_work_factor": schema.Int64Attribute{
+				Description: "The maximum scrypt work factor (log2 of the cost) accepted with passphrase, between 1 and 30, defaults to 22 like age.",
+				Optional:    true,
+				Validators:  []validator.Int64{int64validator.Between(1, 30)},
+			},
+			"content": schema.StringAttribute{
+				Description: "The decrypted content.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"content_base64": schema.StringAttribute{
+				Description: "The base64 encoded decrypted content, for binary content.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// Open decrypts the content.
+func (e *ageDecryptEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data ageDecryptEphemeralResourceModel
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var agePrivateKeys []string
+	diags = data.AgePrivateKeys.ElementsAs(ctx, &agePrivateKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agePrivateKey := strings.Join(agePrivateKeys, "\n")
+	var passphrase *AgePassphrase
+	if !data.Passphrase.IsNull() {
+		passphrase = &AgePassphrase{
+			Passphrase: data.Passphrase.ValueString(),
+			WorkFactor: int(data.PassphraseMaxWorkFactor.ValueInt64()),
+		}
+	} else {
+		agePrivateKey = e.providerData.agePrivateKey(agePrivateKeys)
+	}
+
+	content, err := AgeDecryptData(data.Encrypted.ValueString(), agePrivateKey, passphrase)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Decrypting Content",
+			fmt.Sprintf("Could not decrypt content: %s", err),
+		)
+		return
+	}
+
+	data.Content = types.StringValue(string(content))
+	data.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(content))
+
+	diags = resp.Result.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
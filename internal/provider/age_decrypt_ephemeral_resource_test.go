@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgeDecryptEphemeralResource(t *testing.T) {
+	encrypted, err := AgeEncryptData("secret", []string{agePubkey}, nil, false)
+	assert.NoError(t, err)
+
+	config := func(agePrivateKey string) string {
+		return fmt.Sprintf(`
+					ephemeral "sopsage_age_decrypted" "test" {
+					  encrypted = "%s"
+					  age_private_keys = ["%s"]
+					}`, encrypted, agePrivateKey)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config(agePrivkey),
+			},
+			{
+				Config:      config(otherAgePrivkey),
+				ExpectError: regexp.MustCompile("Error Decrypting Content"),
+			},
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &ageEncryptFunction{}
+)
+
+// NewAgeEncryptFunction is a helper function to simplify the provider implementation.
+func NewAgeEncryptFunction() function.Function {
+	return &ageEncryptFunction{}
+}
+
+// ageEncryptFunction is the function implementation.
+type ageEncryptFunction struct {
+}
+
+// Metadata returns the function name.
+func (f *ageEncryptFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "age_encrypt"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *ageEncryptFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Encrypts content with age, without SOPS.",
+		Description: "Encrypts content with age for the given public keys. The output is random, " +
+			"so it changes on every evaluation and is best stored through the sopsage_age_encrypted resource.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "content",
+				Description: "The content to encrypt.",
+			},
+			function.ListParameter{
+				Name:        "age_public_keys",
+				Description: "List of age public keys to encrypt with. Hybrid post-quantum age keys, age plugin recipients and SSH public keys are accepted too.",
+				ElementType: types.StringType,
+			},
+			function.BoolParameter{
+				Name:        "armor",
+				Description: "Output ASCII armor instead of base64 encoded binary.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run encrypts the content.
+func (f *ageEncryptFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var agePublicKeys []string
+	var armored bool
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &content, &agePublicKeys, &armored))
+	if resp.Error != nil {
+		return
+	}
+
+	encrypted, err := AgeEncryptData(content, agePublicKeys, nil, armored)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewFuncError(fmt.Sprintf("Could not encrypt content: %s", err)))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, encrypted))
+}
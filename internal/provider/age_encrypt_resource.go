@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &ageEncryptResource{}
+	_ resource.ResourceWithConfigure      = &ageEncryptResource{}
+	_ resource.ResourceWithValidateConfig = &ageEncryptResource{}
+)
+
+// NewAgeEncryptResource is a helper function to simplify the provider implementation.
+func NewAgeEncryptResource() resource.Resource {
+	return &ageEncryptResource{}
+}
+
+// ageEncryptResource is the resource implementation.
+type ageEncryptResource struct {
+}
+
+// ageEncryptResourceModel maps the resource schema data.
+type ageEncryptResourceModel struct {
+	ID                   types.String `tfsdk:"id"`
+	Content              types.String `tfsdk:"content"`
+	AgePublicKeys        types.List   `tfsdk:"age_public_keys"`
+	Passphrase           types.String `tfsdk:"passphrase"`
+	PassphraseWorkFactor types.Int64  `tfsdk:"passphrase_work_factor"`
+	Armor                types.Bool   `tfsdk:"armor"`
+	Encrypted            types.String `tfsdk:"encrypted"`
+}
+
+// Configure adds the provider configured client to the resource.
+func (r *ageEncryptResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	// No configuration needed for this resource
+}
+
+// Metadata returns the resource type name.
+func (r *ageEncryptResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_age_encrypted"
+}
+
+// Schema defines the schema for the resource.
+func (r *ageEncryptResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Encrypts content with age, without SOPS.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"content": schema.StringAttribute{
+				Description: "The content to encrypt.",
+				Required:    true,
+				Sensitive:   true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"age_public_keys": schema.ListAttribute{
+				Description: "List of age public keys to encrypt with. Hybrid post-quantum age keys, age plugin recipients and SSH public keys are accepted too. " +
+					"Exactly one of age_public_keys or passphrase must be set.",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"passphrase": schema.StringAttribute{
+				Description: "Passphrase to encrypt with, as an age scrypt recipient. age does not allow a passphrase to be combined " +
+					"with other recipients, so it can't be used together with age_public_keys.",
+				Optional:  true,
+				Sensitive: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"passphrase_work_factor": schema.Int64Attribute{
+				Description: "The scrypt work factor (log2 of the cost) used with passphrase, between 1 and 30, defaults to 18 like age.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"armor": schema.BoolAttribute{
+				Description:   "Output ASCII armor instead of base64 encoded binary, defaults to true.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+				Default:       booldefault.StaticBool(true),
+			},
+			"encrypted": schema.StringAttribute{
+				Description: "The encrypted content.",
+				Computed:    true,
+				Sensitive:   false,
+			},
+		},
+	}
+}
+
+// ValidateConfig validates the recipients and the passphrase work factor.
+func (r *ageEncryptResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config ageEncryptResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.AgePublicKeys.IsUnknown() || config.Passphrase.IsUnknown() {
+		return
+	}
+	if config.AgePublicKeys.IsNull() == config.Passphrase.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("passphrase"),
+			"Invalid Recipients",
+			"Exactly one of age_public_keys or passphrase must be set, age does not allow a passphrase to be combined with other recipients.",
+		)
+	}
+
+	if config.PassphraseWorkFactor.IsNull() || config.PassphraseWorkFactor.IsUnknown() {
+		return
+	}
+	if config.Passphrase.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("passphrase_work_factor"),
+			"Invalid Work Factor",
+			"passphrase_work_factor can only be set together with passphrase.",
+		)
+		return
+	}
+	if workFactor := config.PassphraseWorkFactor.ValueInt64(); workFactor < 1 || workFactor > 30 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("passphrase_work_factor"),
+			"Invalid Work Factor",
+			fmt.Sprintf("The scrypt work factor must be between 1 and 30, got %d.", workFactor),
+		)
+	}
+}
+
+// Create creates a new encrypted content.
+func (r *ageEncryptResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan ageEncryptResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the age public keys
+	var agePublicKeys []string
+	diags = plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var passphrase *AgePassphrase
+	if !plan.Passphrase.IsNull() {
+		passphrase = &AgePassphrase{
+			Passphrase: plan.Passphrase.ValueString(),
+			WorkFactor: int(plan.PassphraseWorkFactor.ValueInt64()),
+		}
+	}
+
+	// Encrypt the content
+	encrypted, err := AgeEncryptData(plan.Content.ValueString(), agePublicKeys, passphrase, plan.Armor.ValueBool())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Encrypting Content",
+			fmt.Sprintf("Could not encrypt content: %s", err),
+		)
+		return
+	}
+
+	// Generate a unique ID based on the encrypted content
+	h := sha256.New()
+	h.Write([]byte(encrypted))
+	id := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	// Set resource ID
+	plan.ID = types.StringValue(id)
+	// Set encrypted content
+	plan.Encrypted = types.StringValue(encrypted)
+
+	// Set state to computed values
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *ageEncryptResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state ageEncryptResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *ageEncryptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError(
+		"Resource Does Not Support Update",
+		"The sopsage_age_encrypted resource does not support updates. All changes require resource replacement.",
+	)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *ageEncryptResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Encrypted content doesn't have any external resources to clean up
+	// The state will be removed by Terraform automatically
+}
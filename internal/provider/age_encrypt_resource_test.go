@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAgeEncryptResource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_age_encrypted" "test" {
+					  content = "secret"
+					  age_public_keys = ["%s"]
+					}
+
+					data "sopsage_age_decrypted" "test" {
+					  encrypted = sopsage_age_encrypted.test.encrypted
+					  age_private_keys = ["%s"]
+					}`, agePubkey, agePrivkey),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("sopsage_age_encrypted.test", "armor", "true"),
+					resource.TestMatchResourceAttr("sopsage_age_encrypted.test", "encrypted", regexp.MustCompile(`^-----BEGIN AGE ENCRYPTED FILE-----`)),
+					resource.TestCheckResourceAttr("data.sopsage_age_decrypted.test", "content", "secret"),
+					resource.TestCheckResourceAttr("data.sopsage_age_decrypted.test", "content_base64", "c2VjcmV0"),
+				),
+			},
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_age_encrypted" "test" {
+					  content = "secret"
+					  age_public_keys = ["%s"]
+					  armor = false
+					}
+
+					data "sopsage_age_decrypted" "test" {
+					  encrypted = sopsage_age_encrypted.test.encrypted
+					  age_private_keys = ["%s"]
+					}`, agePubkey, agePrivkey),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestMatchResourceAttr("sopsage_age_encrypted.test", "encrypted", regexp.MustCompile(`^[A-Za-z0-9+/]+=*$`)),
+					resource.TestCheckResourceAttr("data.sopsage_age_decrypted.test", "content", "secret"),
+				),
+			},
+		},
+	})
+}
+
+func TestAgeEncryptResourcePassphrase(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_age_encrypted" "test" {
+					  content = "secret"
+					  age_public_keys = ["%s"]
+					  passphrase = "correct horse battery staple"
+					}`, agePubkey),
+				ExpectError: regexp.MustCompile(`Exactly one of age_public_keys or passphrase must be set`),
+			},
+			{
+				Config: `
+					resource "sopsage_age_encrypted" "test" {
+					  content = "secret"
+					  passphrase = "correct horse battery staple"
+					  passphrase_work_factor = 31
+					}`,
+				ExpectError: regexp.MustCompile(`must be between 1 and 30`),
+			},
+			{
+				Config: `
+					data "sopsage_age_decrypted" "test" {
+					  encrypted = "unused"
+					  passphrase = "correct horse battery staple"
+					  passphrase_max_work_factor = 0
+					}`,
+				ExpectError: regexp.MustCompile(`must be between 1 and 30`),
+			},
+			{
+				Config: `
+					resource "sopsage_age_encrypted" "test" {
+					  content = "secret"
+					  passphrase = "correct horse battery staple"
+					  passphrase_work_factor = 10
+					}
+
+					data "sopsage_age_decrypted" "test" {
+					  encrypted = sopsage_age_encrypted.test.encrypted
+					  passphrase = "correct horse battery staple"
+					  passphrase_max_work_factor = 10
+					}`,
+				Check: resource.TestCheckResourceAttr("data.sopsage_age_decrypted.test", "content", "secret"),
+			},
+		},
+	})
+}
+
+func TestAgeEncryptFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					output "test" {
+					  value = provider::sopsage::age_encrypt("secret", ["invalid-key"], true)
+					}`,
+				ExpectError: regexp.MustCompile(`Could not encrypt\s+content`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					data "sopsage_age_decrypted" "test" {
+					  encrypted = provider::sopsage::age_encrypt("secret", ["%s"], true)
+					  age_private_keys = ["%s"]
+					}`, agePubkey, agePrivkey),
+				Check: resource.TestCheckResourceAttr("data.sopsage_age_decrypted.test", "content", "secret"),
+			},
+		},
+	})
+}
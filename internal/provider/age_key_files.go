@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DefaultAgeKeyFiles returns the age identity files the sops CLI reads by default: $SOPS_AGE_KEY_FILE when set, and
+// sops/age/keys.txt in the user configuration directory.
+func DefaultAgeKeyFiles() []string {
+	var filenames []string
+	if ageKeyFile := os.Getenv("SOPS_AGE_KEY_FILE"); ageKeyFile != "" {
+		filenames = append(filenames, ageKeyFile)
+	}
+	configDir, err := sopsUserConfigDir()
+	if err != nil {
+		return filenames
+	}
+	return append(filenames, filepath.Join(configDir, "sops", "age", "keys.txt"))
+}
+
+// EnvironmentAgeIdentities returns the identities found in the SOPS_AGE_KEY, SOPS_AGE_KEY_FILE and
+// SOPS_AGE_SSH_PRIVATE_KEY_FILE environment variables, one per line.
+func EnvironmentAgeIdentities() (string, error) {
+	var identities []string
+	if ageKey := os.Getenv("SOPS_AGE_KEY"); ageKey != "" {
+		if _, err := parseAgeIdentities(ageKey); err != nil {
+			return "", fmt.Errorf("failed to parse SOPS_AGE_KEY: %w", err)
+		}
+		identities = append(identities, ageKey)
+	}
+	if ageKeyFile := os.Getenv("SOPS_AGE_KEY_FILE"); ageKeyFile != "" {
+		fileIdentities, err := LoadAgeKeyFiles([]string{ageKeyFile}, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to load SOPS_AGE_KEY_FILE: %w", err)
+		}
+		identities = append(identities, fileIdentities)
+	}
+	if sshKeyFile := os.Getenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE"); sshKeyFile != "" {
+		fileIdentities, err := LoadAgeKeyFiles([]string{sshKeyFile}, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to load SOPS_AGE_SSH_PRIVATE_KEY_FILE: %w", err)
+		}
+		identities = append(identities, fileIdentities)
+	}
+	return strings.Join(identities, "\n"), nil
+}
+
+// sopsUserConfigDir returns the user configuration directory like sops does, honouring XDG_CONFIG_HOME on macOS too.
+func sopsUserConfigDir() (string, error) {
+	if runtime.GOOS == "darwin" {
+		if configDir := os.Getenv("XDG_CONFIG_HOME"); configDir != "" {
+			return configDir, nil
+		}
+	}
+	return os.UserConfigDir()
+}
+
+// LoadAgeKeyFiles reads age identity files and returns their identities, one per line. Comment and blank lines are
+// dropped. Missing files are skipped when ignoreMissing is set, which is how the default locations are treated.
+func LoadAgeKeyFiles(filenames []string, ignoreMissing bool) (string, error) {
+	var identities []string
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if ignoreMissing && errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			identities = append(identities, line)
+		}
+		if _, err := parseAgeIdentities(string(data)); err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", filename, err)
+		}
+	}
+	return strings.Join(identities, "\n"), nil
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeAgeKeyFile(t *testing.T, dir string, identities ...string) string {
+	t.Helper()
+	filename := filepath.Join(dir, "keys.txt")
+	content := "# created: 2024-01-01T00:00:00Z\n"
+	for _, identity := range identities {
+		content += "# public key: ...\n" + identity + "\n\n"
+	}
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filename, []byte(content), 0o600))
+	return filename
+}
+
+func TestLoadAgeKeyFiles(t *testing.T) {
+	filename := writeAgeKeyFile(t, t.TempDir(), agePrivkey, otherAgePrivkey)
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+
+	identities, err := LoadAgeKeyFiles([]string{filename, missing}, true)
+	require.NoError(t, err)
+	assert.Equal(t, agePrivkey+"\n"+otherAgePrivkey, identities)
+
+	_, err = LoadAgeKeyFiles([]string{filename, missing}, false)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	invalid := filepath.Join(t.TempDir(), "invalid.txt")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a key\n"), 0o600))
+	_, err = LoadAgeKeyFiles([]string{invalid}, true)
+	assert.ErrorContains(t, err, "failed to parse "+invalid)
+}
+
+func TestDefaultAgeKeyFiles(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	assert.Equal(t, []string{filepath.Join(configDir, "sops", "age", "keys.txt")}, DefaultAgeKeyFiles())
+
+	t.Setenv("SOPS_AGE_KEY_FILE", "/run/secrets/age.txt")
+	assert.Equal(t, []string{"/run/secrets/age.txt", filepath.Join(configDir, "sops", "age", "keys.txt")}, DefaultAgeKeyFiles())
+}
+
+func TestEnvironmentAgeIdentities(t *testing.T) {
+	sshKeyFile := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(sshKeyFile, []byte(sshPrivkey), 0o600))
+	t.Setenv("SOPS_AGE_KEY", agePrivkey)
+	t.Setenv("SOPS_AGE_KEY_FILE", writeAgeKeyFile(t, t.TempDir(), otherAgePrivkey))
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE", sshKeyFile)
+
+	identities, err := EnvironmentAgeIdentities()
+	require.NoError(t, err)
+	parsed, err := parseAgeIdentities(identities)
+	require.NoError(t, err)
+	assert.Len(t, parsed, 3)
+
+	encrypted, err := AgeEncryptData("hello", []string{sshPubkey}, nil, true)
+	require.NoError(t, err)
+	decrypted, err := AgeDecryptData(encrypted, identities, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(decrypted))
+
+	t.Setenv("SOPS_AGE_KEY_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+	_, err = EnvironmentAgeIdentities()
+	assert.ErrorContains(t, err, "SOPS_AGE_KEY_FILE")
+}
+
+func TestProviderAgeKeyFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE", "")
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+
+	filename := filepath.Join(t.TempDir(), "test.enc.yaml")
+	encrypted, err := SopsEncryptDataFromAgeKeys("foo: bar\n", "yaml", []string{agePubkey}, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filename, []byte(encrypted), 0o600))
+	ageEncrypted, err := AgeEncryptData("hello", []string{agePubkey}, nil, true)
+	require.NoError(t, err)
+
+	keyFile := writeAgeKeyFile(t, t.TempDir(), otherAgePrivkey, agePrivkey)
+	writeAgeKeyFile(t, filepath.Join(configDir, "sops", "age"), agePrivkey)
+
+	dataSources := fmt.Sprintf(`
+		data "sopsage_decrypted_file" "test" {
+		  filename = "%s"
+		}
+
+		data "sopsage_age_decrypted" "test" {
+		  encrypted = %q
+		}`, filename, ageEncrypted)
+
+	contentChecks := []statecheck.StateCheck{
+		statecheck.ExpectKnownValue(
+			"data.sopsage_decrypted_file.test",
+			tfjsonpath.New("content"),
+			knownvalue.StringExact("foo: bar\n"),
+		),
+		statecheck.ExpectKnownValue(
+			"data.sopsage_age_decrypted.test",
+			tfjsonpath.New("content"),
+			knownvalue.StringExact("hello"),
+		),
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					provider "sopsage" {
+					  age_key_file = "%s"
+					}`, filepath.Join(t.TempDir(), "missing.txt")) + dataSources,
+				ExpectError: regexp.MustCompile("Invalid Age Key File"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					provider "sopsage" {
+					  age_key_files = ["%s"]
+					}`, keyFile) + dataSources,
+				ConfigStateChecks: contentChecks,
+			},
+			{
+				Config:            dataSources,
+				ConfigStateChecks: contentChecks,
+			},
+			{
+				Config: fmt.Sprintf(`
+					data "sopsage_age_decrypted" "inline" {
+					  encrypted        = %q
+					  age_private_keys = ["%s"]
+					}`, ageEncrypted, otherAgePrivkey),
+				ExpectError: regexp.MustCompile("Could not decrypt content"),
+			},
+		},
+	})
+}
+
+func TestProviderEnvironmentIdentities(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_SSH_PRIVATE_KEY_FILE", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", writeAgeKeyFile(t, t.TempDir(), agePrivkey))
+
+	filename := filepath.Join(t.TempDir(), "test.enc.yaml")
+	encrypted, err := SopsEncryptDataFromAgeKeys("foo: bar\n", "yaml", []string{agePubkey}, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filename, []byte(encrypted), 0o600))
+	keyFile := writeAgeKeyFile(t, t.TempDir(), otherAgePrivkey)
+
+	dataSource := fmt.Sprintf(`
+		data "sopsage_decrypted_file" "test" {
+		  filename = "%s"
+		}`, filename)
+	contentCheck := []statecheck.StateCheck{
+		statecheck.ExpectKnownValue(
+			"data.sopsage_decrypted_file.test",
+			tfjsonpath.New("content"),
+			knownvalue.StringExact("foo: bar\n"),
+		),
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					provider "sopsage" {
+					  environment_identities = "sometimes"
+					}` + dataSource,
+				ExpectError: regexp.MustCompile("Invalid Environment Identities"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					provider "sopsage" {
+					  environment_identities = "only"
+					  age_key_file           = "%s"
+					}`, keyFile) + dataSource,
+				ExpectError: regexp.MustCompile("Conflicting Age Identity Settings"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					provider "sopsage" {
+					  environment_identities = "ignore"
+					  age_key_file           = "%s"
+					}`, keyFile) + dataSource,
+				ExpectError: regexp.MustCompile("Error Decrypting File"),
+			},
+			{
+				// The environment is ignored by default.
+				Config: fmt.Sprintf(`
+					provider "sopsage" {
+					  age_key_file = "%s"
+					}`, keyFile) + dataSource,
+				ExpectError: regexp.MustCompile("Error Decrypting File"),
+			},
+			{
+				// SOPS_AGE_KEY_FILE remains a default age key file.
+				Config: `
+					provider "sopsage" {
+					  environment_identities = "ignore"
+					}` + dataSource,
+				ConfigStateChecks: contentCheck,
+			},
+			{
+				Config: fmt.Sprintf(`
+					provider "sopsage" {
+					  environment_identities = "merge"
+					  age_key_file           = "%s"
+					}`, keyFile) + dataSource,
+				ConfigStateChecks: contentCheck,
+			},
+			{
+				Config: `
+					provider "sopsage" {
+					  environment_identities = "only"
+					}` + dataSource,
+				ConfigStateChecks: contentCheck,
+			},
+		},
+	})
+}
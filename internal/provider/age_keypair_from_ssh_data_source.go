@@ -2,8 +2,6 @@ package provider
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
 	"fmt"
 	sshage "github.com/Mic92/ssh-to-age"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
@@ -88,16 +86,14 @@ func (d *ageKeyPairFromSSHDataSource) Read(ctx context.Context, req datasource.R
 			"Error Converting SSH Private Key to Age",
 			fmt.Sprintf("Could not convert SSH private key to age: %s", err),
 		)
+		return
 	}
 
-	h := sha256.New()
-	h.Write([]byte(sshPrivateKey))
-	id := base64.StdEncoding.EncodeToString(h.Sum(nil))
-
 	state.AgePrivateKey = types.StringValue(*priv)
 	state.AgePublicKey = types.StringValue(*pub)
 
-	state.ID = types.StringValue(id)
+	// The public key identifies the key pair without revealing the private key
+	state.ID = types.StringValue(*pub)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
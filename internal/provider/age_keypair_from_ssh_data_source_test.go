@@ -32,6 +32,11 @@ func TestAgeKeyPairFromSSHDataSource(t *testing.T) {
 						tfjsonpath.New("age_private_key"),
 						knownvalue.StringExact(agePrivkey),
 					),
+					statecheck.ExpectKnownValue(
+						"data.sopsage_keypair_from_ssh.test",
+						tfjsonpath.New("id"),
+						knownvalue.StringExact(agePubkey),
+					),
 				},
 			},
 		},
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/plugin"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAgePluginName is the name of a stand-in age plugin served by the test binary itself. Its recipients and
+// identities wrap a regular X25519 key, so the files it encrypts can also be decrypted with that key.
+const fakeAgePluginName = "sopsagefake"
+
+var (
+	fakeAgePluginRecipient = plugin.EncodeRecipient(fakeAgePluginName, []byte(agePubkey))
+	fakeAgePluginIdentity  = plugin.EncodeIdentity(fakeAgePluginName, []byte(agePrivkey))
+)
+
+func TestMain(m *testing.M) {
+	if filepath.Base(os.Args[0]) == "age-plugin-"+fakeAgePluginName {
+		os.Exit(fakeAgePluginMain())
+	}
+	os.Exit(m.Run())
+}
+
+func fakeAgePluginMain() int {
+	p, err := plugin.New(fakeAgePluginName)
+	if err != nil {
+		return 1
+	}
+	p.RegisterFlags(flag.NewFlagSet(fakeAgePluginName, flag.ContinueOnError))
+	p.HandleRecipient(func(data []byte) (age.Recipient, error) {
+		return age.ParseX25519Recipient(string(data))
+	})
+	p.HandleIdentity(func(data []byte) (age.Identity, error) {
+		return age.ParseX25519Identity(string(data))
+	})
+	return p.Main()
+}
+
+// withFakeAgePlugin puts the fake age plugin on PATH for the duration of the test.
+func withFakeAgePlugin(t *testing.T) {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Symlink(executable, filepath.Join(dir, "age-plugin-"+fakeAgePluginName)); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestAgePluginRecipients(t *testing.T) {
+	withFakeAgePlugin(t)
+
+	t.Run("age", func(t *testing.T) {
+		encrypted, err := AgeEncryptData("secret", []string{fakeAgePluginRecipient}, nil, true)
+		assert.NoError(t, err)
+		for _, identity := range []string{agePrivkey, fakeAgePluginIdentity} {
+			decrypted, err := AgeDecryptData(encrypted, identity, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, "secret", string(decrypted))
+		}
+	})
+
+	t.Run("sops", func(t *testing.T) {
+		data := `{"foo": "bar"}`
+		encrypted, err := SopsEncryptDataFromAgeKeys(data, "json", []string{fakeAgePluginRecipient, otherAgePubkey}, nil)
+		assert.NoError(t, err)
+		for _, identity := range []string{agePrivkey, fakeAgePluginIdentity, otherAgePrivkey} {
+			decrypted, err := SopsDecryptDataFromAgeKey(encrypted, "json", identity)
+			assert.NoError(t, err)
+			assert.JSONEq(t, data, decrypted)
+		}
+	})
+
+	t.Run("missing plugin", func(t *testing.T) {
+		recipient := plugin.EncodeRecipient("sopsagemissing", []byte(agePubkey))
+		_, err := AgeEncryptData("secret", []string{recipient}, nil, true)
+		assert.Error(t, err)
+	})
+}
+
+func TestSopsEncryptResourceAgePlugin(t *testing.T) {
+	withFakeAgePlugin(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  content = "foo: bar"
+					  format = "yaml"
+					  age_public_keys = ["%s"]
+					}`, fakeAgePluginRecipient),
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					_, err := SopsDecryptDataFromAgeKey(value, "yaml", agePrivkey)
+					return err
+				}),
+			},
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource = &agePublicKeyFromPrivateKeyDataSource{}
+)
+
+// NewAgePublicKeyFromPrivateKeyDataSource is a helper function to simplify the provider implementation.
+func NewAgePublicKeyFromPrivateKeyDataSource() datasource.DataSource {
+	return &agePublicKeyFromPrivateKeyDataSource{}
+}
+
+// agePublicKeyFromPrivateKeyDataSource is the data source implementation.
+type agePublicKeyFromPrivateKeyDataSource struct {
+}
+
+// agePublicKeyFromPrivateKeyDataSourceModel maps the data source schema data.
+type agePublicKeyFromPrivateKeyDataSourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	AgePrivateKey types.String `tfsdk:"age_private_key"`
+	AgePublicKey  types.String `tfsdk:"age_public_key"`
+}
+
+// Metadata returns the data source type name.
+func (d *agePublicKeyFromPrivateKeyDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_public_key_from_age_private_key"
+}
+
+// Schema defines the schema for the data source.
+func (d *agePublicKeyFromPrivateKeyDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Derives the age public key, that is the recipient, of an age private key.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the data source.",
+				Computed:    true,
+			},
+			"age_private_key": schema.StringAttribute{
+				Description: "The age private key (AGE-SECRET-KEY-1...) or hybrid post-quantum age private key (AGE-SECRET-KEY-PQ-1...).",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"age_public_key": schema.StringAttribute{
+				Description: "The age public key of the private key.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *agePublicKeyFromPrivateKeyDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state agePublicKeyFromPrivateKeyDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	agePublicKey, err := AgePublicKeyFromPrivateKey(state.AgePrivateKey.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("age_private_key"),
+			"Invalid Age Private Key",
+			fmt.Sprintf("Could not derive the age public key: %s", err),
+		)
+		return
+	}
+
+	// Identify the data source by its public key, keeping any digest of the private key out of the state.
+	h := sha256.Sum256([]byte(agePublicKey))
+	state.ID = types.StringValue(base64.StdEncoding.EncodeToString(h[:]))
+	state.AgePublicKey = types.StringValue(agePublicKey)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
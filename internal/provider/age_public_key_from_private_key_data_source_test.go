@@ -0,0 +1,111 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgePublicKeyFromPrivateKey(t *testing.T) {
+	hybrid, err := age.GenerateHybridIdentity()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		agePrivateKey string
+		expected      string
+		expectedError string
+	}{
+		{name: "x25519", agePrivateKey: agePrivkey, expected: agePubkey},
+		{name: "surrounding whitespace", agePrivateKey: " " + agePrivkey + "\n", expected: agePubkey},
+		{name: "hybrid", agePrivateKey: hybrid.String(), expected: hybrid.Recipient().String()},
+		{name: "empty", agePrivateKey: "", expectedError: "empty age private key"},
+		{name: "several keys", agePrivateKey: agePrivkey + "\n" + otherAgePrivkey, expectedError: "several lines"},
+		{name: "public key", agePrivateKey: agePubkey, expectedError: "is an age public key"},
+		{name: "plugin identity", agePrivateKey: fakeAgePluginIdentity, expectedError: "plugin identities"},
+		{name: "ssh key", agePrivateKey: sshPubkey, expectedError: "SSH keys"},
+		{name: "unknown prefix", agePrivateKey: "AGE-KEY-1ABC", expectedError: "start with AGE-SECRET-KEY-1"},
+		{name: "lower case", agePrivateKey: strings.ToLower(agePrivkey), expectedError: "upper case"},
+		{name: "checksum", agePrivateKey: agePrivkey[:len(agePrivkey)-1] + "Q", expectedError: "invalid checksum"},
+		{name: "invalid character", agePrivateKey: agePrivkey[:20] + "B" + agePrivkey[21:], expectedError: "invalid character"},
+		{name: "truncated", agePrivateKey: "AGE-SECRET-KEY-1", expectedError: "malformed secret key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agePublicKey, err := AgePublicKeyFromPrivateKey(tt.agePrivateKey)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, agePublicKey)
+		})
+	}
+}
+
+func TestAgePublicKeyFromPrivateKeyDataSource(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "sopsage_public_key_from_age_private_key" "test" {
+					  age_private_key = "%s"
+					}`, agePubkey),
+				ExpectError: regexp.MustCompile("Invalid Age Private Key"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					data "sopsage_public_key_from_age_private_key" "test" {
+					  age_private_key = "%s"
+					}`, agePrivkey),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sopsage_public_key_from_age_private_key.test",
+						tfjsonpath.New("age_public_key"),
+						knownvalue.StringExact(agePubkey),
+					),
+				},
+			},
+		},
+	})
+}
+
+func TestAgePublicKeyFromPrivateKeyFunction(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					output "test" {
+					  value = provider::sopsage::public_key_from_age_private_key("AGE-SECRET-KEY-1")
+					}`,
+				ExpectError: regexp.MustCompile(`Could not derive the age\s+public key`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::public_key_from_age_private_key("%s")
+					}`, otherAgePrivkey),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(otherAgePubkey)),
+				},
+			},
+		},
+	})
+}
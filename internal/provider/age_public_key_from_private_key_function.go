@@ -0,0 +1,60 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &agePublicKeyFromPrivateKeyFunction{}
+)
+
+// NewAgePublicKeyFromPrivateKeyFunction is a helper function to simplify the provider implementation.
+func NewAgePublicKeyFromPrivateKeyFunction() function.Function {
+	return &agePublicKeyFromPrivateKeyFunction{}
+}
+
+// agePublicKeyFromPrivateKeyFunction is the function implementation.
+type agePublicKeyFromPrivateKeyFunction struct {
+}
+
+// Metadata returns the function name.
+func (f *agePublicKeyFromPrivateKeyFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "public_key_from_age_private_key"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *agePublicKeyFromPrivateKeyFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary:     "Derives the age public key of an age private key.",
+		Description: "Returns the age public key, that is the recipient, of an age private key or hybrid post-quantum age private key.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "age_private_key",
+				Description: "The age private key (AGE-SECRET-KEY-1...) or hybrid post-quantum age private key (AGE-SECRET-KEY-PQ-1...).",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run derives the public key.
+func (f *agePublicKeyFromPrivateKeyFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var agePrivateKey string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &agePrivateKey))
+	if resp.Error != nil {
+		return
+	}
+
+	agePublicKey, err := AgePublicKeyFromPrivateKey(agePrivateKey)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewArgumentFuncError(0, fmt.Sprintf("Could not derive the age public key: %s", err)))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, agePublicKey))
+}
@@ -1,10 +1,20 @@
 package provider
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"slices"
 	"strings"
-	"sync"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"filippo.io/age/armor"
+	"filippo.io/age/plugin"
 
 	"github.com/getsops/sops/v3/config"
 
@@ -13,9 +23,8 @@ import (
 	keysource "github.com/getsops/sops/v3/age"
 	"github.com/getsops/sops/v3/cmd/sops/common"
 	"github.com/getsops/sops/v3/cmd/sops/formats"
-	"github.com/getsops/sops/v3/decrypt"
+	"github.com/getsops/sops/v3/hcvault"
 	"github.com/getsops/sops/v3/keys"
-	"github.com/getsops/sops/v3/keyservice"
 	"github.com/getsops/sops/v3/version"
 	sf "github.com/sa-/slicefunk"
 )
@@ -27,50 +36,176 @@ type EncryptionConfig struct {
 	EncryptedRegex          string
 	UnencryptedCommentRegex string
 	EncryptedCommentRegex   string
+	// MACOnlyEncrypted computes the MAC over the encrypted values only, leaving cleartext values free to change.
+	MACOnlyEncrypted bool
+	// DataKey, when set, is reused instead of generating a fresh data key.
+	DataKey []byte
+	// PgpPublicKeys are armored PGP public keys to encrypt for, alongside the age public keys.
+	PgpPublicKeys []string
+	// VaultTransitKeys are Vault transit keys to encrypt for, alongside the age public keys.
+	VaultTransitKeys []VaultTransitKey
+	// KeyServices configures the key services encrypting the data key.
+	KeyServices *KeyServiceConfig
+	// Stores configures the output formatting of the SOPS stores, the sops defaults are used when nil.
+	Stores *config.StoresConfig
+	// DeterministicSeed, when set, derives the data key, the IVs and the age encryption of the data key from the seed
+	// and the content instead of drawing them at random. Experimental: only X25519 age public keys are supported, and
+	// setting any other key type, a data key or key services is an error.
+	DeterministicSeed []byte
+}
+
+// DecryptionConfig holds what is needed to recover the data key of a SOPS document.
+type DecryptionConfig struct {
+	// AgePrivateKey holds one or more newline separated age private keys. Ambient SOPS_* variables are never read.
+	AgePrivateKey string
+	// KeyServices configures the key services decrypting the data key.
+	KeyServices *KeyServiceConfig
 }
 
+// DefaultEncryptionConfig returns the encryption configuration used when none is given. Like the resources, whose
+// encryption rules all default to "", it sets no rule, so the default _unencrypted suffix of SOPS applies.
 func DefaultEncryptionConfig() *EncryptionConfig {
+	return &EncryptionConfig{}
+}
+
+// metadata returns the SOPS metadata recording the encryption rules. Without any rule, the default _unencrypted suffix
+// is recorded, as SOPS applies it when loading a document that has none.
+func (c *EncryptionConfig) metadata() sops.Metadata {
+	metadata := sops.Metadata{
+		UnencryptedSuffix:       c.UnencryptedSuffix,
+		EncryptedSuffix:         c.EncryptedSuffix,
+		UnencryptedRegex:        c.UnencryptedRegex,
+		EncryptedRegex:          c.EncryptedRegex,
+		UnencryptedCommentRegex: c.UnencryptedCommentRegex,
+		EncryptedCommentRegex:   c.EncryptedCommentRegex,
+		MACOnlyEncrypted:        c.MACOnlyEncrypted,
+	}
+	if c.UnencryptedSuffix == "" && c.EncryptedSuffix == "" && c.UnencryptedRegex == "" && c.EncryptedRegex == "" &&
+		c.UnencryptedCommentRegex == "" && c.EncryptedCommentRegex == "" {
+		metadata.UnencryptedSuffix = sops.DefaultUnencryptedSuffix
+	}
+	return metadata
+}
+
+// EncryptionConfigFromMetadata returns the encryption rules recorded in the metadata of a SOPS document.
+func EncryptionConfigFromMetadata(metadata sops.Metadata) *EncryptionConfig {
 	return &EncryptionConfig{
-		UnencryptedSuffix: "_unencrypted",
+		UnencryptedSuffix:       metadata.UnencryptedSuffix,
+		EncryptedSuffix:         metadata.EncryptedSuffix,
+		UnencryptedRegex:        metadata.UnencryptedRegex,
+		EncryptedRegex:          metadata.EncryptedRegex,
+		UnencryptedCommentRegex: metadata.UnencryptedCommentRegex,
+		EncryptedCommentRegex:   metadata.EncryptedCommentRegex,
+		MACOnlyEncrypted:        metadata.MACOnlyEncrypted,
 	}
 }
 
-func SopsEncryptDataFromAgeKeys(data string, format string, agePublicKeys []string, encryptionConfig *EncryptionConfig) (string, error) {
-	if encryptionConfig == nil {
-		encryptionConfig = DefaultEncryptionConfig()
+// sopsStore returns the SOPS store of a format, formatting its output with stores, or like the sops CLI when nil.
+func sopsStore(format string, stores *config.StoresConfig) common.Store {
+	if stores == nil {
+		stores = config.NewStoresConfig()
+	}
+	return common.StoreForFormat(formats.FormatFromString(format), stores)
+}
+
+// SopsReformatData emits an encrypted SOPS document again with the output formatting of stores. The values and the
+// metadata are kept as they are, so no key is needed and the MAC stays valid.
+func SopsReformatData(data string, format string, stores *config.StoresConfig) (string, error) {
+	return SopsConvertData(data, format, format, stores)
+}
+
+// SopsConvertData emits an encrypted SOPS document of format from in format to, formatted with stores. The values
+// and the MAC do not depend on the serialization, so no key is needed and the converted document decrypts to the
+// same values. Only the YAML store can hold several documents.
+func SopsConvertData(data string, from string, to string, stores *config.StoresConfig) (string, error) {
+	tree, err := sopsStore(from, nil).LoadEncryptedFile([]byte(data))
+	if err != nil {
+		return "", err
 	}
+	if len(tree.Branches) > 1 && formats.FormatFromString(to) != formats.Yaml {
+		return "", fmt.Errorf("the %s format cannot hold the %d documents of the source", to, len(tree.Branches))
+	}
+	result, err := sopsStore(to, stores).EmitEncryptedFile(tree)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// SopsLoadMetadata parses an encrypted SOPS document and returns its metadata without decrypting it.
+func SopsLoadMetadata(data string, format string) (sops.Metadata, error) {
 	store := common.StoreForFormat(
 		formats.FormatFromString(format),
 		config.NewStoresConfig(),
 	)
 
+	tree, err := store.LoadEncryptedFile([]byte(data))
+	if err != nil {
+		return sops.Metadata{}, err
+	}
+	return tree.Metadata, nil
+}
+
+func SopsEncryptDataFromAgeKeys(data string, format string, agePublicKeys []string, encryptionConfig *EncryptionConfig) (string, error) {
+	if encryptionConfig == nil {
+		encryptionConfig = DefaultEncryptionConfig()
+	}
+	if encryptionConfig.DeterministicSeed != nil {
+		if err := checkDeterministicConfig(encryptionConfig); err != nil {
+			return "", err
+		}
+	}
+	store := sopsStore(format, encryptionConfig.Stores)
+
 	branches, err := store.LoadPlainFile([]byte(data))
 	if err != nil {
 		return "", err
 	}
+	branches = dropEmptyDocuments(branches)
 
 	masterKeys, err := keysource.MasterKeysFromRecipients(strings.Join(agePublicKeys, ","))
 	if err != nil {
 		return "", err
 	}
+	keyGroup := sops.KeyGroup(sf.Map(masterKeys, func(key *keysource.MasterKey) keys.MasterKey { return keys.MasterKey(key) }))
+
+	keyService := newSopsKeyService(encryptionConfig.KeyServices)
+	pgpKeys, err := keyService.addPgpPublicKeys(encryptionConfig.PgpPublicKeys)
+	if err != nil {
+		return "", err
+	}
+	keyGroup = append(keyGroup, pgpKeys...)
+	for _, key := range encryptionConfig.VaultTransitKeys {
+		keyGroup = append(keyGroup, hcvault.NewMasterKey(key.Address, key.EnginePath, key.KeyName))
+	}
+	keyServices := keyServiceClients(encryptionConfig.KeyServices, keyService)
 
 	tree := sops.Tree{
 		Branches: branches,
-		Metadata: sops.Metadata{
-			KeyGroups:               []sops.KeyGroup{sf.Map(masterKeys, func(key *keysource.MasterKey) keys.MasterKey { return keys.MasterKey(key) })},
-			Version:                 version.Version,
-			UnencryptedSuffix:       encryptionConfig.UnencryptedSuffix,
-			EncryptedSuffix:         encryptionConfig.EncryptedSuffix,
-			UnencryptedRegex:        encryptionConfig.UnencryptedRegex,
-			EncryptedRegex:          encryptionConfig.EncryptedRegex,
-			UnencryptedCommentRegex: encryptionConfig.UnencryptedCommentRegex,
-			EncryptedCommentRegex:   encryptionConfig.EncryptedCommentRegex,
-		},
-	}
-
-	dataKey, errs := tree.GenerateDataKeyWithKeyServices(
-		[]keyservice.KeyServiceClient{keyservice.NewLocalClient()},
-	)
+		Metadata: encryptionConfig.metadata(),
+	}
+	tree.Metadata.KeyGroups = []sops.KeyGroup{keyGroup}
+	tree.Metadata.Version = version.Version
+
+	if encryptionConfig.DeterministicSeed != nil {
+		err = encryptTreeDeterministic(&tree, encryptionConfig.DeterministicSeed, format, data, agePublicKeys)
+		if err != nil {
+			return "", err
+		}
+		result, err := store.EmitEncryptedFile(tree)
+		if err != nil {
+			return "", err
+		}
+		return string(result), nil
+	}
+
+	dataKey := encryptionConfig.DataKey
+	var errs []error
+	if dataKey == nil {
+		dataKey, errs = tree.GenerateDataKeyWithKeyServices(keyServices)
+	} else {
+		errs = tree.Metadata.UpdateMasterKeysWithKeyServices(dataKey, keyServices)
+	}
 	if len(errs) > 0 {
 		return "", fmt.Errorf("could not use provider age keys for encryption: %s", errs)
 	}
@@ -91,34 +226,376 @@ func SopsEncryptDataFromAgeKeys(data string, format string, agePublicKeys []stri
 	return string(result), nil
 }
 
-var decryptMutex = sync.Mutex{}
+// dropEmptyDocuments removes the empty documents of a multi-document YAML input, such as the one following a
+// trailing "---" separator, which would otherwise be emitted as documents holding nothing but SOPS metadata.
+func dropEmptyDocuments(branches sops.TreeBranches) sops.TreeBranches {
+	documents := sf.Filter(branches, func(branch sops.TreeBranch) bool { return len(branch) > 0 })
+	if len(documents) == 0 {
+		return branches
+	}
+	return documents
+}
+
+// SopsFormatForPath infers the format of a file from its extension, like the sops CLI does.
+func SopsFormatForPath(path string) string {
+	switch formats.FormatForPath(path) {
+	case formats.Yaml:
+		return "yaml"
+	case formats.Json:
+		return "json"
+	case formats.Dotenv:
+		return "dotenv"
+	case formats.Ini:
+		return "ini"
+	default:
+		return "binary"
+	}
+}
+
+// SopsDecryptData decrypts a SOPS document and verifies its MAC.
+func SopsDecryptData(data string, format string, decryptionConfig *DecryptionConfig) (string, error) {
+	store := common.StoreForFormat(
+		formats.FormatFromString(format),
+		config.NewStoresConfig(),
+	)
+
+	tree, err := store.LoadEncryptedFile([]byte(data))
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := sopsDataKey(tree.Metadata, decryptionConfig)
+	if err != nil {
+		return "", err
+	}
+
+	cipher := aes.NewCipher()
+	mac, err := tree.Decrypt(dataKey, cipher)
+	if err != nil {
+		return "", err
+	}
+	originalMac, err := cipher.Decrypt(
+		tree.Metadata.MessageAuthenticationCode,
+		dataKey,
+		tree.Metadata.LastModified.Format(time.RFC3339),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt original mac: %w", err)
+	}
+	if originalMac != mac {
+		return "", fmt.Errorf("failed to verify data integrity. expected mac %q, got %q", originalMac, mac)
+	}
+
+	decrypted, err := store.EmitPlainFile(tree.Branches)
+	if err != nil {
+		return "", err
+	}
+	return string(decrypted), nil
+}
 
 func SopsDecryptDataFromAgeKey(data string, format string, agePrivateKey string) (string, error) {
-	decryptMutex.Lock()
-	defer decryptMutex.Unlock()
+	return SopsDecryptData(data, format, &DecryptionConfig{AgePrivateKey: agePrivateKey})
+}
 
-	storedSOPSEnv := make(map[string]string)
-	for _, env := range os.Environ() {
-		if strings.HasPrefix(env, "SOPS_") {
-			parts := strings.SplitN(env, "=", 2)
-			storedSOPSEnv[parts[0]] = parts[1]
-			os.Unsetenv(parts[0])
+// SopsDecryptFile reads and decrypts a SOPS file. The format is inferred from the file extension when empty.
+func SopsDecryptFile(filename string, format string, decryptionConfig *DecryptionConfig) (string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	if format == "" {
+		format = SopsFormatForPath(filename)
+	}
+	return SopsDecryptData(string(data), format, decryptionConfig)
+}
+
+// sopsDataKey recovers the data key of a SOPS document from its metadata.
+func sopsDataKey(metadata sops.Metadata, decryptionConfig *DecryptionConfig) ([]byte, error) {
+	identities, err := parseAgeIdentities(decryptionConfig.AgePrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	keyService := newSopsKeyService(decryptionConfig.KeyServices)
+	keyService.ageIdentities = identities
+
+	return metadata.GetDataKeyWithKeyServices(keyServiceClients(decryptionConfig.KeyServices, keyService), nil)
+}
+
+// SopsDataKeyFromAgeKey recovers the data key of an encrypted SOPS document.
+func SopsDataKeyFromAgeKey(data string, format string, agePrivateKey string) ([]byte, error) {
+	metadata, err := SopsLoadMetadata(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return sopsDataKey(metadata, &DecryptionConfig{AgePrivateKey: agePrivateKey})
+}
+
+// SopsReencryptDataFromAgeKeys decrypts a SOPS document with decryptionConfig and encrypts it again for agePublicKeys,
+// keeping the encryption rules of the source document. The data key is preserved unless rotateDataKey is set. The
+// output is formatted with stores, or like the sops CLI when nil.
+func SopsReencryptDataFromAgeKeys(data string, format string, decryptionConfig *DecryptionConfig, agePublicKeys []string, rotateDataKey bool, stores *config.StoresConfig) (string, error) {
+	metadata, err := SopsLoadMetadata(data, format)
+	if err != nil {
+		return "", err
+	}
+	encryptionConfig := EncryptionConfigFromMetadata(metadata)
+	encryptionConfig.KeyServices = decryptionConfig.KeyServices
+	encryptionConfig.Stores = stores
+
+	plaintext, err := SopsDecryptData(data, format, decryptionConfig)
+	if err != nil {
+		return "", err
+	}
+
+	if !rotateDataKey {
+		encryptionConfig.DataKey, err = sopsDataKey(metadata, decryptionConfig)
+		if err != nil {
+			return "", err
 		}
 	}
 
-	os.Setenv("SOPS_AGE_KEY", agePrivateKey)
-	defer os.Unsetenv("SOPS_AGE_KEY")
+	return SopsEncryptDataFromAgeKeys(plaintext, format, agePublicKeys, encryptionConfig)
+}
 
-	defer func() {
-		for key, value := range storedSOPSEnv {
-			os.Setenv(key, value)
+// SopsRemovedRecipients returns the recipients of a SOPS document, such as age public keys or PGP fingerprints, that
+// are not among agePublicKeys.
+func SopsRemovedRecipients(data string, format string, agePublicKeys []string) ([]string, error) {
+	metadata, err := SopsLoadMetadata(data, format)
+	if err != nil {
+		return nil, err
+	}
+	kept := sf.Map(agePublicKeys, strings.TrimSpace)
+	var removed []string
+	for _, group := range metadata.KeyGroups {
+		for _, key := range group {
+			if recipient := key.ToString(); !slices.Contains(kept, recipient) {
+				removed = append(removed, recipient)
+			}
 		}
-	}()
+	}
+	return removed, nil
+}
+
+// agePluginUI is the UI offered to age plugins. Terraform has no terminal to prompt on, so plugins that need input,
+// such as a PIN, fail instead of hanging.
+var agePluginUI = &plugin.ClientUI{
+	DisplayMessage: func(name, message string) error {
+		log.Printf("[INFO] age-plugin-%s: %s", name, message)
+		return nil
+	},
+	RequestValue: func(name, prompt string, _ bool) (string, error) {
+		return "", fmt.Errorf("age-plugin-%s requested input (%q), which is not supported", name, prompt)
+	},
+	Confirm: func(name, prompt, _, _ string) (bool, error) {
+		return false, fmt.Errorf("age-plugin-%s requested a confirmation (%q), which is not supported", name, prompt)
+	},
+}
+
+// parseAgeRecipient parses an age public key, a hybrid post-quantum age public key, an age plugin recipient, or an SSH
+// public key. Plugin recipients are handled by the matching age-plugin-* binary found on PATH.
+func parseAgeRecipient(recipient string) (age.Recipient, error) {
+	recipient = strings.TrimSpace(recipient)
+	switch {
+	case strings.HasPrefix(recipient, "age1pq1"):
+		return age.ParseHybridRecipient(recipient)
+	case strings.HasPrefix(recipient, "age1") && strings.Count(recipient, "1") > 1:
+		return plugin.NewRecipient(recipient, agePluginUI)
+	case strings.HasPrefix(recipient, "age1"):
+		return age.ParseX25519Recipient(recipient)
+	case strings.HasPrefix(recipient, "ssh-"):
+		return agessh.ParseRecipient(recipient)
+	}
+	return nil, fmt.Errorf("unknown recipient type: %q", recipient)
+}
+
+// AgePassphrase is an age scrypt passphrase.
+type AgePassphrase struct {
+	Passphrase string
+	// WorkFactor is the log2 of the scrypt cost used to encrypt, or the maximum
+	// one accepted to decrypt. Zero keeps the age default.
+	WorkFactor int
+}
+
+func checkScryptWorkFactor(workFactor int) error {
+	if workFactor < 1 || workFactor > 30 {
+		return fmt.Errorf("invalid scrypt work factor %d, must be between 1 and 30", workFactor)
+	}
+	return nil
+}
 
-	decrypted, err := decrypt.Data([]byte(data), format)
+// AgeEncryptData encrypts data with age for agePublicKeys or for passphrase, as ASCII armor or as base64 encoded binary.
+// age does not allow a passphrase to be combined with other recipients.
+func AgeEncryptData(data string, agePublicKeys []string, passphrase *AgePassphrase, armored bool) (string, error) {
+	recipients := make([]age.Recipient, 0, len(agePublicKeys)+1)
+	for _, key := range agePublicKeys {
+		recipient, err := parseAgeRecipient(key)
+		if err != nil {
+			return "", err
+		}
+		recipients = append(recipients, recipient)
+	}
+	if passphrase != nil {
+		if len(recipients) > 0 {
+			return "", fmt.Errorf("a passphrase can't be combined with other recipients")
+		}
+		recipient, err := age.NewScryptRecipient(passphrase.Passphrase)
+		if err != nil {
+			return "", err
+		}
+		if passphrase.WorkFactor != 0 {
+			if err := checkScryptWorkFactor(passphrase.WorkFactor); err != nil {
+				return "", err
+			}
+			recipient.SetWorkFactor(passphrase.WorkFactor)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("no age public keys or passphrase provided")
+	}
+
+	var out bytes.Buffer
+	var dst io.Writer = &out
+	var armorWriter io.WriteCloser
+	if armored {
+		armorWriter = armor.NewWriter(&out)
+		dst = armorWriter
+	}
+
+	w, err := age.Encrypt(dst, recipients...)
 	if err != nil {
 		return "", err
 	}
+	if _, err := io.WriteString(w, data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
 
-	return string(decrypted), nil
+	if !armored {
+		return base64.StdEncoding.EncodeToString(out.Bytes()), nil
+	}
+	if err := armorWriter.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// parseAgeIdentities parses newline separated age private keys, hybrid post-quantum age private keys and age plugin
+// identities, as well as unencrypted PEM encoded SSH private keys. Empty lines and comments are ignored.
+func parseAgeIdentities(agePrivateKey string) ([]age.Identity, error) {
+	var identities []age.Identity
+	lines := strings.Split(agePrivateKey, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var identity age.Identity
+		var err error
+		switch {
+		case strings.HasPrefix(line, "-----BEGIN "):
+			end := i
+			for end < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[end]), "-----END ") {
+				end++
+			}
+			if end == len(lines) {
+				return nil, fmt.Errorf("unterminated PEM block")
+			}
+			identity, err = agessh.ParseIdentity([]byte(strings.Join(lines[i:end+1], "\n") + "\n"))
+			i = end
+		case strings.HasPrefix(line, "AGE-PLUGIN-"):
+			identity, err = plugin.NewIdentity(line, agePluginUI)
+		case strings.HasPrefix(line, "AGE-SECRET-KEY-PQ-1"):
+			identity, err = age.ParseHybridIdentity(line)
+		case strings.HasPrefix(line, "AGE-SECRET-KEY-1"):
+			identity, err = age.ParseX25519Identity(line)
+		default:
+			err = fmt.Errorf("unknown identity type")
+		}
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
+
+// AgePublicKeyFromPrivateKey returns the age public key of an age private key or hybrid post-quantum age private key.
+// Errors describe the usual mistakes, such as passing a public key or a plugin identity, before the bech32 ones.
+func AgePublicKeyFromPrivateKey(agePrivateKey string) (string, error) {
+	agePrivateKey = strings.TrimSpace(agePrivateKey)
+	upper := strings.ToUpper(agePrivateKey)
+	switch {
+	case agePrivateKey == "":
+		return "", fmt.Errorf("empty age private key")
+	case strings.Contains(agePrivateKey, "\n"):
+		return "", fmt.Errorf("expected a single age private key, found several lines")
+	case strings.HasPrefix(upper, "AGE1"):
+		return "", fmt.Errorf("%q is an age public key, not a private key", agePrivateKey)
+	case strings.HasPrefix(upper, "AGE-PLUGIN-"):
+		return "", fmt.Errorf("age plugin identities do not encode their public key, ask the plugin for the recipient")
+	case strings.HasPrefix(upper, "-----BEGIN ") || strings.HasPrefix(agePrivateKey, "ssh-"):
+		return "", fmt.Errorf("SSH keys are not age private keys, use sopsage_public_key_from_ssh with the SSH public key")
+	case !strings.HasPrefix(upper, "AGE-SECRET-KEY-1") && !strings.HasPrefix(upper, "AGE-SECRET-KEY-PQ-1"):
+		return "", fmt.Errorf("age private keys start with AGE-SECRET-KEY-1 or AGE-SECRET-KEY-PQ-1")
+	case agePrivateKey != upper:
+		// bech32 accepts lower case strings, age only upper case private keys.
+		return "", fmt.Errorf("age private keys are upper case, found lower case characters")
+	case strings.HasPrefix(agePrivateKey, "AGE-SECRET-KEY-PQ-1"):
+		identity, err := age.ParseHybridIdentity(agePrivateKey)
+		if err != nil {
+			return "", err
+		}
+		return identity.Recipient().String(), nil
+	default:
+		identity, err := age.ParseX25519Identity(agePrivateKey)
+		if err != nil {
+			return "", err
+		}
+		return identity.Recipient().String(), nil
+	}
+}
+
+// AgeDecryptData decrypts ASCII armored or base64 encoded age data with one or more newline separated age private keys
+// and/or a passphrase.
+func AgeDecryptData(encrypted string, agePrivateKey string, passphrase *AgePassphrase) ([]byte, error) {
+	identities, err := parseAgeIdentities(agePrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if passphrase != nil {
+		identity, err := age.NewScryptIdentity(passphrase.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if passphrase.WorkFactor != 0 {
+			if err := checkScryptWorkFactor(passphrase.WorkFactor); err != nil {
+				return nil, err
+			}
+			identity.SetMaxWorkFactor(passphrase.WorkFactor)
+		}
+		identities = append(identities, identity)
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no age private keys or passphrase provided")
+	}
+
+	var src io.Reader
+	trimmed := strings.TrimSpace(encrypted)
+	if strings.HasPrefix(trimmed, armor.Header) {
+		src = armor.NewReader(strings.NewReader(trimmed))
+	} else {
+		raw, err := base64.StdEncoding.DecodeString(trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("encrypted data is neither ASCII armored nor base64 encoded: %w", err)
+		}
+		src = bytes.NewReader(raw)
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
 }
@@ -2,9 +2,12 @@ package provider
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSopsEncryptDataFromAgeKeys(t *testing.T) {
@@ -15,6 +18,7 @@ func TestSopsEncryptDataFromAgeKeys(t *testing.T) {
 		agePublicKeys           []string
 		wantErr                 bool
 		encryptionConfig        *EncryptionConfig
+		expectedEncryptedKeys   []string
 		expectedUnEncryptedKeys []string
 	}{
 		{
@@ -25,6 +29,16 @@ func TestSopsEncryptDataFromAgeKeys(t *testing.T) {
 			wantErr:                 false,
 			expectedUnEncryptedKeys: []string{"test_unencrypted"},
 		},
+		{
+			name:                    "valid json encryption with encrypted_regex",
+			data:                    `{"test": "value", "test_unencrypted": "value"}`,
+			format:                  "json",
+			agePublicKeys:           []string{agePubkey},
+			wantErr:                 false,
+			encryptionConfig:        &EncryptionConfig{EncryptedRegex: "_unencrypted$"},
+			expectedEncryptedKeys:   []string{"test_unencrypted"},
+			expectedUnEncryptedKeys: []string{"test"},
+		},
 		{
 			name:                    "valid json encryption with unencrypted_regex",
 			data:                    `{"test": "value", "_foo": "bar"}`,
@@ -68,6 +82,14 @@ func TestSopsEncryptDataFromAgeKeys(t *testing.T) {
 				t.Fatal(err)
 			}
 
+			decrypted, err := SopsDecryptDataFromAgeKey(result, tc.format, agePrivkey)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.data, decrypted)
+
+			for _, key := range tc.expectedEncryptedKeys {
+				assert.Contains(t, parsedOutput, key)
+				assert.NotEqual(t, parsedInput[key], parsedOutput[key])
+			}
 			for _, key := range tc.expectedUnEncryptedKeys {
 				assert.Contains(t, parsedInput, key)
 				assert.Contains(t, parsedOutput, key)
@@ -115,3 +137,255 @@ func TestSopsDecryptDataFromAgeKey(t *testing.T) {
 		})
 	}
 }
+
+func TestSopsMACOnlyEncrypted(t *testing.T) {
+	data := "password: secret\nreplicas: 1\n"
+	for _, macOnlyEncrypted := range []bool{false, true} {
+		t.Run(fmt.Sprintf("mac_only_encrypted=%t", macOnlyEncrypted), func(t *testing.T) {
+			encrypted, err := SopsEncryptDataFromAgeKeys(data, "yaml", []string{agePubkey}, &EncryptionConfig{
+				EncryptedRegex:   "^password$",
+				MACOnlyEncrypted: macOnlyEncrypted,
+			})
+			require.NoError(t, err)
+			require.Contains(t, encrypted, "replicas: 1\n")
+
+			// Other tooling edits a cleartext value
+			edited := strings.Replace(encrypted, "replicas: 1\n", "replicas: 3\n", 1)
+			decrypted, err := SopsDecryptDataFromAgeKey(edited, "yaml", agePrivkey)
+			if !macOnlyEncrypted {
+				assert.ErrorContains(t, err, "failed to verify data integrity")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "password: secret\nreplicas: 3\n", decrypted)
+
+			reencrypted, err := SopsReencryptDataFromAgeKeys(edited, "yaml", &DecryptionConfig{AgePrivateKey: agePrivkey}, []string{otherAgePubkey}, false, nil)
+			require.NoError(t, err)
+			metadata, err := SopsLoadMetadata(reencrypted, "yaml")
+			require.NoError(t, err)
+			assert.True(t, metadata.MACOnlyEncrypted)
+		})
+	}
+}
+
+func TestSopsReencryptDataFromAgeKeys(t *testing.T) {
+	testCases := []struct {
+		name            string
+		agePrivateKey   string
+		rotateDataKey   bool
+		wantSameDataKey bool
+		wantErr         bool
+	}{
+		{
+			name:            "keep data key",
+			agePrivateKey:   agePrivkey,
+			rotateDataKey:   false,
+			wantSameDataKey: true,
+		},
+		{
+			name:            "rotate data key",
+			agePrivateKey:   agePrivkey,
+			rotateDataKey:   true,
+			wantSameDataKey: false,
+		},
+		{
+			name:          "wrong private key",
+			agePrivateKey: otherAgePrivkey,
+			wantErr:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := `{"test": "value", "_foo": "bar"}`
+			source, err := SopsEncryptDataFromAgeKeys(data, "json", []string{agePubkey}, &EncryptionConfig{UnencryptedRegex: "^_.*"})
+			assert.NoError(t, err)
+
+			result, err := SopsReencryptDataFromAgeKeys(source, "json", &DecryptionConfig{AgePrivateKey: tc.agePrivateKey}, []string{otherAgePubkey}, tc.rotateDataKey, nil)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			_, err = SopsDecryptDataFromAgeKey(result, "json", agePrivkey)
+			assert.Error(t, err)
+			decrypted, err := SopsDecryptDataFromAgeKey(result, "json", otherAgePrivkey)
+			assert.NoError(t, err)
+			assert.JSONEq(t, data, decrypted)
+
+			metadata, err := SopsLoadMetadata(result, "json")
+			assert.NoError(t, err)
+			assert.Equal(t, "^_.*", metadata.UnencryptedRegex)
+
+			sourceDataKey, err := SopsDataKeyFromAgeKey(source, "json", agePrivkey)
+			assert.NoError(t, err)
+			resultDataKey, err := SopsDataKeyFromAgeKey(result, "json", otherAgePrivkey)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantSameDataKey, string(sourceDataKey) == string(resultDataKey))
+		})
+	}
+}
+
+func TestSopsMultiDocumentYAML(t *testing.T) {
+	testCases := []struct {
+		name             string
+		data             string
+		encryptionConfig *EncryptionConfig
+		want             string
+		// wantDocuments are lines expected in each encrypted document, in order.
+		wantDocuments [][]string
+	}{
+		{
+			name:          "two documents",
+			data:          "a: 1\n---\nb: 2\n",
+			want:          "a: 1\n---\nb: 2\n",
+			wantDocuments: [][]string{{"a: ENC["}, {"b: ENC["}},
+		},
+		{
+			name:          "leading and trailing separators",
+			data:          "---\na: 1\n---\nb: 2\n---\n",
+			want:          "a: 1\n---\nb: 2\n",
+			wantDocuments: [][]string{{"a: ENC["}, {"b: ENC["}},
+		},
+		{
+			name:             "encryption rules apply to every document",
+			data:             "kind: Secret\ndata:\n    a: b\n---\nkind: Secret\ndata:\n    c: d\n",
+			encryptionConfig: &EncryptionConfig{EncryptedRegex: "^data$"},
+			want:             "kind: Secret\ndata:\n    a: b\n---\nkind: Secret\ndata:\n    c: d\n",
+			wantDocuments: [][]string{
+				{"kind: Secret", "data:", "    a: ENC["},
+				{"kind: Secret", "data:", "    c: ENC["},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encrypted, err := SopsEncryptDataFromAgeKeys(tc.data, "yaml", []string{agePubkey}, tc.encryptionConfig)
+			assert.NoError(t, err)
+			documents := strings.Split(encrypted, "\n---\n")
+			require.Len(t, documents, len(tc.wantDocuments))
+			for i, document := range documents {
+				assert.Contains(t, document, "\nsops:\n")
+				lines := strings.Split(document, "\n")
+				for j, want := range tc.wantDocuments[i] {
+					assert.True(t, strings.HasPrefix(lines[j], want), "document %d line %d: %q does not start with %q", i, j, lines[j], want)
+				}
+			}
+
+			decrypted, err := SopsDecryptDataFromAgeKey(encrypted, "yaml", agePrivkey)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, decrypted)
+
+			// The MAC covers every document, so none of them can be dropped
+			_, err = SopsDecryptDataFromAgeKey(documents[0], "yaml", agePrivkey)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSopsFormatForPath(t *testing.T) {
+	testCases := map[string]string{
+		"secrets.enc.yaml": "yaml",
+		"secrets.yml":      "yaml",
+		"secrets.json":     "json",
+		"secrets.env":      "dotenv",
+		"secrets.ini":      "ini",
+		"secrets.pem":      "binary",
+		"secrets":          "binary",
+	}
+
+	for path, want := range testCases {
+		t.Run(path, func(t *testing.T) {
+			assert.Equal(t, want, SopsFormatForPath(path))
+		})
+	}
+}
+
+func TestAgeEncryptData(t *testing.T) {
+	testCases := []struct {
+		name          string
+		agePublicKeys []string
+		armored       bool
+		wantErr       bool
+	}{
+		{
+			name:          "armored",
+			agePublicKeys: []string{agePubkey},
+			armored:       true,
+		},
+		{
+			name:          "base64",
+			agePublicKeys: []string{agePubkey},
+			armored:       false,
+		},
+		{
+			name:          "multiple recipients including ssh",
+			agePublicKeys: []string{otherAgePubkey, sshPubkey, agePubkey},
+			armored:       true,
+		},
+		{
+			name:          "invalid recipient",
+			agePublicKeys: []string{"invalid-key"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data := "secret\x00binary"
+			encrypted, err := AgeEncryptData(data, tc.agePublicKeys, nil, tc.armored)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.armored, strings.HasPrefix(encrypted, "-----BEGIN AGE ENCRYPTED FILE-----"))
+
+			decrypted, err := AgeDecryptData(encrypted, agePrivkey, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, data, string(decrypted))
+
+			_, err = AgeDecryptData(encrypted, "invalid-key", nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestAgeEncryptDataWithPassphrase(t *testing.T) {
+	passphrase := &AgePassphrase{Passphrase: "correct horse battery staple", WorkFactor: 10}
+
+	encrypted, err := AgeEncryptData("secret", nil, passphrase, true)
+	assert.NoError(t, err)
+
+	decrypted, err := AgeDecryptData(encrypted, "", passphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(decrypted))
+
+	decrypted, err = AgeDecryptData(encrypted, agePrivkey, passphrase)
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", string(decrypted))
+
+	_, err = AgeDecryptData(encrypted, "", &AgePassphrase{Passphrase: "wrong", WorkFactor: 10})
+	assert.Error(t, err)
+
+	_, err = AgeDecryptData(encrypted, "", &AgePassphrase{Passphrase: passphrase.Passphrase, WorkFactor: 9})
+	assert.Error(t, err, "work factor above the accepted maximum")
+
+	_, err = AgeDecryptData(encrypted, "", nil)
+	assert.Error(t, err)
+
+	_, err = AgeEncryptData("secret", []string{agePubkey}, passphrase, true)
+	assert.Error(t, err, "passphrase combined with other recipients")
+
+	_, err = AgeEncryptData("secret", nil, &AgePassphrase{Passphrase: "x", WorkFactor: 31}, true)
+	assert.Error(t, err)
+
+	assert.Error(t, checkScryptWorkFactor(0))
+	assert.NoError(t, checkScryptWorkFactor(1))
+	assert.NoError(t, checkScryptWorkFactor(30))
+
+	_, err = AgeEncryptData("secret", nil, nil, true)
+	assert.Error(t, err)
+}
@@ -15,3 +15,7 @@ const agePrivkey = "AGE-SECRET-KEY-1PNY5XN43NRND2QQNVS972JPYPL93Z6HL8LFHPYWEGVX5
 const sshPubkey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIJi1jN7AvcahdNe67qWI1WVdgzR4BvCwcqhqlTtirdRY"
 
 const agePubkey = "age1c2cnfzjfeswsydufz4tcrs46zqpmnz9t3dwz5uaef5yl3qnzaptqy88dl7"
+
+const otherAgePrivkey = "AGE-SECRET-KEY-1NVUCG6AANNG5JXP4NYY0HPXHL2YLFT4YFAA4XA2MEZ376QC9W52QLTZJS0"
+
+const otherAgePubkey = "age1874u0y7rw8c4f97ywkcr9qh2wu0wt08t0nqafwp9ml0g9jup8cpszwu3mh"
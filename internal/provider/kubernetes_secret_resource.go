@@ -0,0 +1,313 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// kubernetesSecretEncryptedComment precedes the secret payload in the manifest, so that only the payload is encrypted
+// and metadata stays readable by Flux and kubectl. Unlike a key regex, which SOPS matches at any depth, the comment only
+// applies to the top-level field it precedes, so a label or an annotation named data stays in cleartext.
+const kubernetesSecretEncryptedComment = "sopsage:encrypted"
+
+// kubernetesSecretEncryptedCommentRegex matches kubernetesSecretEncryptedComment, SOPS keeps the space after the #.
+const kubernetesSecretEncryptedCommentRegex = `^\s*` + kubernetesSecretEncryptedComment + "$"
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource              = &kubernetesSecretResource{}
+	_ resource.ResourceWithConfigure = &kubernetesSecretResource{}
+)
+
+// NewKubernetesSecretResource is a helper function to simplify the provider implementation.
+func NewKubernetesSecretResource() resource.Resource {
+	return &kubernetesSecretResource{}
+}
+
+// kubernetesSecretResource is the resource implementation.
+type kubernetesSecretResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// kubernetesSecretResourceModel maps the resource schema data.
+type kubernetesSecretResourceModel struct {
+	ID            types.String `tfsdk:"id"`
+	Name          types.String `tfsdk:"name"`
+	Namespace     types.String `tfsdk:"namespace"`
+	Type          types.String `tfsdk:"type"`
+	Labels        types.Map    `tfsdk:"labels"`
+	Annotations   types.Map    `tfsdk:"annotations"`
+	Data          types.Map    `tfsdk:"data"`
+	StringData    types.Map    `tfsdk:"string_data"`
+	AgePublicKeys types.List   `tfsdk:"age_public_keys"`
+	Encrypted     types.String `tfsdk:"encrypted"`
+}
+
+// kubernetesSecretManifest is the Secret manifest, fields are ordered like kubectl output.
+type kubernetesSecretManifest struct {
+	APIVersion string                           `json:"apiVersion"`
+	Kind       string                           `json:"kind"`
+	Metadata   kubernetesSecretManifestMetadata `json:"metadata"`
+	Type       string                           `json:"type,omitempty"`
+	Data       map[string]string                `json:"data,omitempty"`
+	StringData map[string]string                `json:"stringData,omitempty"`
+}
+
+type kubernetesSecretManifestMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// yaml returns the manifest in YAML, with a kubernetesSecretEncryptedComment before data and stringData. Values are
+// written in JSON, which is valid YAML, and the SOPS YAML store keeps the field order when loading them.
+func (m kubernetesSecretManifest) yaml() (string, error) {
+	type field struct {
+		key       string
+		value     any
+		encrypted bool
+	}
+	fields := []field{{"apiVersion", m.APIVersion, false}, {"kind", m.Kind, false}, {"metadata", m.Metadata, false}}
+	if m.Type != "" {
+		fields = append(fields, field{"type", m.Type, false})
+	}
+	if len(m.Data) > 0 {
+		fields = append(fields, field{"data", m.Data, true})
+	}
+	if len(m.StringData) > 0 {
+		fields = append(fields, field{"stringData", m.StringData, true})
+	}
+
+	var b strings.Builder
+	for _, f := range fields {
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return "", err
+		}
+		if f.encrypted {
+			b.WriteString("# " + kubernetesSecretEncryptedComment + "\n")
+		}
+		b.WriteString(f.key + ": " + string(value) + "\n")
+	}
+	return b.String(), nil
+}
+
+// Configure adds the provider data to the resource.
+func (r *kubernetesSecretResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.providerData = providerData
+}
+
+// Metadata returns the resource type name.
+func (r *kubernetesSecretResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_kubernetes_secret"
+}
+
+// Schema defines the schema for the resource.
+func (r *kubernetesSecretResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Generates a Kubernetes Secret manifest encrypted using SOPS with age encryption. " +
+			"Only the top-level data and stringData fields are encrypted, so the manifest can be decrypted by Flux.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the resource, in the namespace/name form, or the name without namespace.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Description: "Name of the secret.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Description: "Namespace of the secret.",
+				Optional:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"type": schema.StringAttribute{
+				Description:   "Type of the secret, defaults to \"Opaque\".",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+				Default:       stringdefault.StaticString("Opaque"),
+			},
+			"labels": schema.MapAttribute{
+				Description: "Labels of the secret.",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"annotations": schema.MapAttribute{
+				Description: "Annotations of the secret.",
+				Optional:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"data": schema.MapAttribute{
+				Description: "Secret values, base64-encoded into the data field of the manifest.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"string_data": schema.MapAttribute{
+				Description: "Secret values, written as is into the stringData field of the manifest.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+			},
+			"age_public_keys": schema.ListAttribute{
+				Description: "List of age public keys to encrypt with.",
+				Required:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"encrypted": schema.StringAttribute{
+				Description: "The encrypted Secret manifest in SOPS YAML format.",
+				Computed:    true,
+				Sensitive:   false,
+			},
+		},
+	}
+}
+
+// Create creates a new encrypted Secret manifest.
+func (r *kubernetesSecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan kubernetesSecretResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	manifest := kubernetesSecretManifest{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata: kubernetesSecretManifestMetadata{
+			Name:      plan.Name.ValueString(),
+			Namespace: plan.Namespace.ValueString(),
+		},
+		Type: plan.Type.ValueString(),
+	}
+	var data map[string]string
+	resp.Diagnostics.Append(plan.Labels.ElementsAs(ctx, &manifest.Metadata.Labels, false)...)
+	resp.Diagnostics.Append(plan.Annotations.ElementsAs(ctx, &manifest.Metadata.Annotations, false)...)
+	resp.Diagnostics.Append(plan.Data.ElementsAs(ctx, &data, false)...)
+	resp.Diagnostics.Append(plan.StringData.ElementsAs(ctx, &manifest.StringData, false)...)
+
+	// Get the age public keys
+	var agePublicKeys []string
+	resp.Diagnostics.Append(plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(data) > 0 {
+		manifest.Data = make(map[string]string, len(data))
+		for key, value := range data {
+			manifest.Data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+	}
+
+	content, err := manifest.yaml()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Generating Secret Manifest",
+			fmt.Sprintf("Could not generate secret manifest: %s", err),
+		)
+		return
+	}
+
+	// Encrypt the manifest
+	encrypted, err := SopsEncryptDataFromAgeKeys(content, "yaml", agePublicKeys, &EncryptionConfig{
+		EncryptedCommentRegex: kubernetesSecretEncryptedCommentRegex,
+		KeyServices:           r.providerData.keyServiceConfig(),
+		Stores:                r.providerData.storesConfig(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Encrypting Content",
+			fmt.Sprintf("Could not encrypt content: %s", err),
+		)
+		return
+	}
+
+	// Set resource ID
+	plan.ID = types.StringValue(manifest.Metadata.Name)
+	if manifest.Metadata.Namespace != "" {
+		plan.ID = types.StringValue(manifest.Metadata.Namespace + "/" + manifest.Metadata.Name)
+	}
+	// Set encrypted content
+	plan.Encrypted = types.StringValue(encrypted)
+
+	// Set state to computed values
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *kubernetesSecretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state kubernetesSecretResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *kubernetesSecretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError(
+		"Resource Does Not Support Update",
+		"The sopsage_kubernetes_secret resource does not support updates. All changes require resource replacement.",
+	)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *kubernetesSecretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Encrypted content doesn't have any external resources to clean up
+	// The state will be removed by Terraform automatically
+}
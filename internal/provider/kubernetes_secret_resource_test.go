@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestKubernetesSecretResource(t *testing.T) {
+	config := fmt.Sprintf(`
+					resource "sopsage_kubernetes_secret" "test" {
+					  name = "test"
+					  namespace = "default"
+					  labels = {app = "test", data = "labelled"}
+					  data = {password = "hunter2"}
+					  string_data = {username = "admin"}
+					  age_public_keys = ["%s"]
+					}
+				`, agePubkey)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("sopsage_kubernetes_secret.test", "id", "default/test"),
+					resource.TestCheckResourceAttrWith("sopsage_kubernetes_secret.test", "encrypted", func(value string) error {
+						if strings.Contains(value, "aHVudGVyMg==") || strings.Contains(value, "admin") {
+							return fmt.Errorf("secret values are not encrypted:\n%s", value)
+						}
+						if !strings.Contains(value, "app: test") || !strings.Contains(value, "data: labelled") {
+							return fmt.Errorf("labels are not left in cleartext:\n%s", value)
+						}
+						decrypted, err := SopsDecryptDataFromAgeKey(value, "yaml", agePrivkey)
+						if err != nil {
+							return err
+						}
+						expected := strings.Join([]string{
+							"apiVersion: v1",
+							"kind: Secret",
+							"metadata:",
+							"    name: test",
+							"    namespace: default",
+							"    labels:",
+							"        app: test",
+							"        data: labelled",
+							"type: Opaque",
+							"# sopsage:encrypted",
+							"data:",
+							"    password: aHVudGVyMg==",
+							"# sopsage:encrypted",
+							"stringData:",
+							"    username: admin",
+							"",
+						}, "\n")
+						if decrypted != expected {
+							return fmt.Errorf("unexpected decrypted manifest:\n%s", decrypted)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestKubernetesSecretResourceWithoutNamespace(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_kubernetes_secret" "test" {
+					  name = "test"
+					  string_data = {username = "admin"}
+					  age_public_keys = ["%s"]
+					}
+				`, agePubkey),
+				Check: resource.TestCheckResourceAttr("sopsage_kubernetes_secret.test", "id", "test"),
+			},
+		},
+	})
+}
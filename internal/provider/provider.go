@@ -2,22 +2,122 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"maps"
+	"strings"
 
+	"github.com/getsops/sops/v3/config"
+	"github.com/getsops/sops/v3/keyservice"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"google.golang.org/grpc"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ provider.Provider = &SopsAgeProvider{}
+	_ provider.Provider                       = &SopsAgeProvider{}
+	_ provider.ProviderWithEphemeralResources = &SopsAgeProvider{}
+	_ provider.ProviderWithFunctions          = &SopsAgeProvider{}
 )
 
 // SopsAgeProvider is the provider implementation.
 type SopsAgeProvider struct {
 	// version is set to the provider version on release.
 	version string
+	// keyServiceConns are the connections to the remote key services of the last configuration.
+	keyServiceConns []*grpc.ClientConn
+}
+
+// sopsAgeProviderModel maps the provider schema data.
+type sopsAgeProviderModel struct {
+	Vault                 *vaultProviderModel `tfsdk:"vault"`
+	KeyServices           types.List          `tfsdk:"keyservices"`
+	EnableLocalKeyService types.Bool          `tfsdk:"enable_local_keyservice"`
+	AgeKeyFile            types.String        `tfsdk:"age_key_file"`
+	AgeKeyFiles           types.List          `tfsdk:"age_key_files"`
+	EnvironmentIdentities types.String        `tfsdk:"environment_identities"`
+	sopsStoreFormattingModel
+}
+
+// vaultProviderModel maps the Vault settings of the provider.
+type vaultProviderModel struct {
+	Address types.String       `tfsdk:"address"`
+	Token   types.String       `tfsdk:"token"`
+	AppRole *vaultAppRoleModel `tfsdk:"approle"`
+}
+
+// vaultAppRoleModel maps the Vault AppRole login settings of the provider.
+type vaultAppRoleModel struct {
+	MountPath types.String `tfsdk:"mount_path"`
+	RoleID    types.String `tfsdk:"role_id"`
+	SecretID  types.String `tfsdk:"secret_id"`
+}
+
+// sopsAgeProviderData is handed to the resources, data sources and ephemeral resources of a configured provider.
+type sopsAgeProviderData struct {
+	keyServices *KeyServiceConfig
+	// ageIdentities holds the newline separated identities of the provider age key files.
+	ageIdentities string
+	// environmentIdentities holds the newline separated identities of the SOPS_* variables, empty when ignored.
+	environmentIdentities string
+	// stores holds the output formatting of the SOPS stores.
+	stores *config.StoresConfig
+}
+
+// keyServiceConfig returns the key service settings of the provider, nil when the provider is not configured yet.
+func (d *sopsAgeProviderData) keyServiceConfig() *KeyServiceConfig {
+	if d == nil {
+		return nil
+	}
+	return d.keyServices
+}
+
+// storesConfig returns the output formatting of the SOPS stores, nil when the provider is not configured yet.
+func (d *sopsAgeProviderData) storesConfig() *config.StoresConfig {
+	if d == nil {
+		return nil
+	}
+	return d.stores
+}
+
+// agePrivateKey returns the inline age private keys, or the identities of the provider age key files when there
+// are none, followed by the environment identities.
+func (d *sopsAgeProviderData) agePrivateKey(agePrivateKeys []string) string {
+	if d == nil {
+		return strings.Join(agePrivateKeys, "\n")
+	}
+	identities := strings.Join(agePrivateKeys, "\n")
+	if identities == "" {
+		identities = d.ageIdentities
+	}
+	if d.environmentIdentities != "" {
+		identities = strings.TrimPrefix(identities+"\n"+d.environmentIdentities, "\n")
+	}
+	return identities
+}
+
+// providerDataFrom returns the provider data passed to a Configure method, nil before the provider is configured.
+func providerDataFrom(providerData any) (*sopsAgeProviderData, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if providerData == nil {
+		return nil, diags
+	}
+	data, ok := providerData.(*sopsAgeProviderData)
+	if !ok {
+		diags.AddError(
+			"Unexpected Provider Data Type",
+			fmt.Sprintf("Expected *sopsAgeProviderData, got: %T. Please report this issue to the provider developers.", providerData),
+		)
+		return nil, diags
+	}
+	return data, diags
 }
 
 // New creates a new provider instance.
@@ -39,13 +139,214 @@ func (p *SopsAgeProvider) Metadata(_ context.Context, _ provider.MetadataRequest
 func (p *SopsAgeProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Description: "Interact with SOPS and Age encryption.",
-		Attributes:  map[string]schema.Attribute{},
+		Attributes: map[string]schema.Attribute{
+			"vault": schema.SingleNestedAttribute{
+				Description: "Vault settings used for Vault transit keys. Without them, VAULT_TOKEN and ~/.vault-token are used like the sops CLI does.",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"address": schema.StringAttribute{
+						Description: "Address of the Vault server to log in to with AppRole, defaults to VAULT_ADDR. " +
+							"Transit keys always use the address they are declared with.",
+						Optional: true,
+					},
+					"token": schema.StringAttribute{
+						Description: "Vault token.",
+						Optional:    true,
+						Sensitive:   true,
+					},
+					"approle": schema.SingleNestedAttribute{
+						Description: "Log in with AppRole to obtain the Vault token.",
+						Optional:    true,
+						Attributes: map[string]schema.Attribute{
+							"mount_path": schema.StringAttribute{
+								Description: "Mount path of the AppRole auth method, defaults to \"approle\".",
+								Optional:    true,
+							},
+							"role_id": schema.StringAttribute{
+								Description: "AppRole role ID.",
+								Required:    true,
+							},
+							"secret_id": schema.StringAttribute{
+								Description: "AppRole secret ID.",
+								Required:    true,
+								Sensitive:   true,
+							},
+						},
+					},
+				},
+			},
+			"keyservices": schema.ListAttribute{
+				Description: "Addresses of sops keyservice servers to delegate data key encryption and decryption to, " +
+					"such as \"unix:///run/sops/keyservice.sock\" or \"tcp://localhost:5000\". They are tried after the local key service.",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"enable_local_keyservice": schema.BoolAttribute{
+				Description: "Use the local key service, defaults to true. Disable it to keep every private identity in the remote key services.",
+				Optional:    true,
+			},
+			"age_key_file": schema.StringAttribute{
+				Description: "Path of an age identity file, used to decrypt when no age_private_keys are given inline.",
+				Optional:    true,
+			},
+			"age_key_files": schema.ListAttribute{
+				Description: "Paths of age identity files, used to decrypt when no age_private_keys are given inline. " +
+					"Without age_key_file and age_key_files, $SOPS_AGE_KEY_FILE and ~/.config/sops/age/keys.txt are read when they " +
+					"exist, like the sops CLI does, whatever environment_identities is, unless it is \"only\".",
+				Optional:    true,
+				ElementType: types.StringType,
+			},
+			"environment_identities": schema.StringAttribute{
+				Description: "Whether the identities of the SOPS_AGE_KEY, SOPS_AGE_KEY_FILE and SOPS_AGE_SSH_PRIVATE_KEY_FILE " +
+					"environment variables are used to decrypt: \"ignore\", \"merge\" to add them to the other identities, " +
+					"or \"only\" to use them instead of the provider age key files. Defaults to \"ignore\", so that ambient variables " +
+					"are never used unless asked for; set \"merge\" to behave like the sops CLI. SOPS_AGE_KEY_FILE is still read " +
+					"as a default age key file when neither age_key_file nor age_key_files is set.",
+				Optional: true,
+			},
+		},
 	}
+	maps.Copy(resp.Schema.Attributes, sopsStoreFormattingProviderAttributes())
 }
 
-// Configure prepares a SOPS Age API client for data sources and resources.
+// Configure prepares the provider data for data sources and resources.
 func (p *SopsAgeProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
-	// No configuration needed for this provider
+	var config sopsAgeProviderModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keyServices := &KeyServiceConfig{
+		DisableLocal: !config.EnableLocalKeyService.IsNull() && !config.EnableLocalKeyService.ValueBool(),
+	}
+
+	var uris []string
+	diags = config.KeyServices.ElementsAs(ctx, &uris, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// Close the connections of a previous configuration, nothing uses them past reconfiguration.
+	for _, conn := range p.keyServiceConns {
+		_ = conn.Close()
+	}
+	p.keyServiceConns = nil
+	for i, uri := range uris {
+		conn, err := DialKeyService(uri)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("keyservices").AtListIndex(i),
+				"Invalid Key Service Address",
+				err.Error(),
+			)
+			return
+		}
+		p.keyServiceConns = append(p.keyServiceConns, conn)
+		keyServices.Remote = append(keyServices.Remote, keyservice.NewKeyServiceClient(conn))
+	}
+	if keyServices.DisableLocal && len(keyServices.Remote) == 0 {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("enable_local_keyservice"),
+			"No Key Service",
+			"The local key service can only be disabled when keyservices are configured.",
+		)
+		return
+	}
+
+	if config.Vault != nil {
+		keyServices.Vault = &VaultConfig{Token: config.Vault.Token.ValueString()}
+		if appRole := config.Vault.AppRole; appRole != nil {
+			if !config.Vault.Token.IsNull() {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("vault").AtName("token"),
+					"Conflicting Vault Settings",
+					"Only one of token or approle can be set.",
+				)
+				return
+			}
+			mountPath := appRole.MountPath.ValueString()
+			if mountPath == "" {
+				mountPath = "approle"
+			}
+			token, err := VaultAppRoleLogin(ctx, config.Vault.Address.ValueString(), mountPath, appRole.RoleID.ValueString(), appRole.SecretID.ValueString())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("vault").AtName("approle"),
+					"Vault AppRole Login Failed",
+					fmt.Sprintf("Could not log in to Vault with AppRole: %s", err),
+				)
+				return
+			}
+			keyServices.Vault.Token = token
+		}
+	}
+
+	environmentIdentities := config.EnvironmentIdentities.ValueString()
+	switch environmentIdentities {
+	case "":
+		environmentIdentities = "ignore"
+	case "ignore", "merge":
+	case "only":
+		if !config.AgeKeyFile.IsNull() || !config.AgeKeyFiles.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("environment_identities"),
+				"Conflicting Age Identity Settings",
+				"age_key_file and age_key_files can't be set when environment_identities is \"only\".",
+			)
+			return
+		}
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("environment_identities"),
+			"Invalid Environment Identities",
+			fmt.Sprintf("environment_identities must be \"ignore\", \"merge\" or \"only\", got %q.", environmentIdentities),
+		)
+		return
+	}
+
+	var ageKeyFiles []string
+	diags = config.AgeKeyFiles.ElementsAs(ctx, &ageKeyFiles, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !config.AgeKeyFile.IsNull() {
+		ageKeyFiles = append([]string{config.AgeKeyFile.ValueString()}, ageKeyFiles...)
+	}
+	defaultAgeKeyFiles := config.AgeKeyFile.IsNull() && config.AgeKeyFiles.IsNull()
+	if defaultAgeKeyFiles && environmentIdentities != "only" {
+		ageKeyFiles = DefaultAgeKeyFiles()
+	}
+	ageIdentities, err := LoadAgeKeyFiles(ageKeyFiles, defaultAgeKeyFiles)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Invalid Age Key File",
+			fmt.Sprintf("Could not load age identities: %s", err),
+		)
+		return
+	}
+
+	providerData := &sopsAgeProviderData{
+		keyServices:   keyServices,
+		ageIdentities: ageIdentities,
+		stores:        config.StoresConfig(nil),
+	}
+	if environmentIdentities != "ignore" {
+		providerData.environmentIdentities, err = EnvironmentAgeIdentities()
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("environment_identities"),
+				"Invalid Environment Identities",
+				fmt.Sprintf("Could not load age identities from the environment: %s", err),
+			)
+			return
+		}
+	}
+	resp.DataSourceData = providerData
+	resp.ResourceData = providerData
+	resp.EphemeralResourceData = providerData
 }
 
 // DataSources defines the data sources implemented in the provider.
@@ -53,6 +354,18 @@ func (p *SopsAgeProvider) DataSources(_ context.Context) []func() datasource.Dat
 	return []func() datasource.DataSource{
 		NewageKeyPairFromSSHDataSource,
 		NewAgePublicKeyFromSSHDataSource,
+		NewAgePublicKeyFromPrivateKeyDataSource,
+		NewSopsDecryptFileDataSource,
+		NewAgeDecryptDataSource,
+	}
+}
+
+// EphemeralResources defines the ephemeral resources implemented in the provider.
+func (p *SopsAgeProvider) EphemeralResources(_ context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewSopsDecryptFileEphemeralResource,
+		NewSopsDecryptTempfileEphemeralResource,
+		NewAgeDecryptEphemeralResource,
 	}
 }
 
@@ -60,5 +373,20 @@ func (p *SopsAgeProvider) DataSources(_ context.Context) []func() datasource.Dat
 func (p *SopsAgeProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewSopsEncryptResource,
+		NewSopsReencryptResource,
+		NewKubernetesSecretResource,
+		NewSopsEncryptFileResource,
+		NewSopsGeneratedSecretResource,
+		NewAgeEncryptResource,
+	}
+}
+
+// Functions defines the functions implemented in the provider.
+func (p *SopsAgeProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewAgeEncryptFunction,
+		NewSopsEncryptDeterministicFunction,
+		NewSopsConvertFunction,
+		NewAgePublicKeyFromPrivateKeyFunction,
 	}
 }
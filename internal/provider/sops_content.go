@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// SopsContentEqual reports whether two plaintext documents of the given format hold the same values, ignoring
+// whitespace, quoting and the order of mapping keys. Binary content is compared byte for byte.
+func SopsContentEqual(format string, a string, b string) (bool, error) {
+	if a == b {
+		return true, nil
+	}
+	if formats.FormatFromString(format) == formats.Binary {
+		return false, nil
+	}
+	store := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+
+	aBranches, err := store.LoadPlainFile([]byte(a))
+	if err != nil {
+		return false, err
+	}
+	bBranches, err := store.LoadPlainFile([]byte(b))
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(normalizeSopsBranches(dropEmptyDocuments(aBranches)), normalizeSopsBranches(dropEmptyDocuments(bBranches))), nil
+}
+
+// normalizeSopsBranches turns documents into values that compare equal regardless of the order of mapping keys.
+// Comments stay attached to the key following them, as comment encryption rules apply to the values below them.
+func normalizeSopsBranches(branches sops.TreeBranches) []any {
+	documents := make([]any, len(branches))
+	for i, branch := range branches {
+		documents[i] = normalizeSopsValue(branch)
+	}
+	return documents
+}
+
+func normalizeSopsValue(value any) any {
+	switch value := value.(type) {
+	case sops.TreeBranch:
+		items := map[any]any{}
+		var comments []string
+		for _, item := range value {
+			if comment, ok := item.Key.(sops.Comment); ok {
+				comments = append(comments, comment.Value)
+				continue
+			}
+			items[item.Key] = []any{comments, normalizeSopsValue(item.Value)}
+			comments = nil
+		}
+		// Trailing comments precede no key
+		return []any{items, comments}
+	case []any:
+		values := make([]any, len(value))
+		for i, v := range value {
+			values[i] = normalizeSopsValue(v)
+		}
+		return values
+	default:
+		return value
+	}
+}
+
+// contentRequiresReplace requires replacement when the content changes, unless the old and new content hold the same
+// values in the planned format.
+func contentRequiresReplace() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			var format basetypes.StringValue
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("format"), &format)...)
+			if resp.Diagnostics.HasError() || format.IsUnknown() {
+				resp.RequiresReplace = true
+				return
+			}
+			equal, err := SopsContentEqual(format.ValueString(), req.StateValue.ValueString(), req.PlanValue.ValueString())
+			resp.RequiresReplace = err != nil || !equal
+		},
+		"Changes to the values of the content require replacement, formatting changes are applied in place.",
+		"Changes to the values of the content require replacement, formatting changes are applied in place.",
+	)
+}
@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/compare"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsContentEqual(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format string
+		a      string
+		b      string
+		equal  bool
+	}{
+		{"json whitespace", "json", `{"a":1,"b":["x","y"]}`, "{\n  \"a\": 1,\n  \"b\": [\"x\", \"y\"]\n}\n", true},
+		{"json key order", "json", `{"a":1,"b":2}`, `{"b":2,"a":1}`, true},
+		{"json list order", "json", `{"a":[1,2]}`, `{"a":[2,1]}`, false},
+		{"json value type", "json", `{"a":1}`, `{"a":"1"}`, false},
+		{"yaml quoting", "yaml", "a: b\nc: 1\n", "\"c\": 1\n'a': \"b\"\n", true},
+		{"yaml value", "yaml", "a: b\n", "a: c\n", false},
+		{"yaml comment", "yaml", "# one\na: b\n", "# two\na: b\n", false},
+		{"yaml comment key order", "yaml", "# one\na: b\nc: d\n", "c: d\n# one\na: b\n", true},
+		{"yaml comment moved", "yaml", "# sopsage:encrypted\na: b\nc: d\n", "a: b\n# sopsage:encrypted\nc: d\n", false},
+		{"dotenv order", "dotenv", "A=1\nB=2\n", "B=2\nA=1\n", true},
+		{"dotenv value", "dotenv", "A=1\n", "A=2\n", false},
+		{"ini order", "ini", "[s]\na = 1\nb = 2\n", "[s]\nb=2\na=1\n", true},
+		{"binary", "binary", "a b", "a  b", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			equal, err := SopsContentEqual(tc.format, tc.a, tc.b)
+			require.NoError(t, err)
+			assert.Equal(t, tc.equal, equal)
+		})
+	}
+
+	_, err := SopsContentEqual("json", `{"a":1}`, "not json")
+	assert.Error(t, err)
+}
+
+func TestSopsEncryptResourceContentFormatting(t *testing.T) {
+	config := func(content string) string {
+		return fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "json"
+					  content = %q
+					  age_public_keys = ["%s"]
+					}
+				`, content, agePubkey)
+	}
+	sameEncrypted := statecheck.CompareValue(compare.ValuesSame())
+	differentEncrypted := statecheck.CompareValue(compare.ValuesDiffer())
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(`{"a":1,"b":"x"}`),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameEncrypted.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+					differentEncrypted.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+				},
+			},
+			{
+				Config: config("{\n  \"b\": \"x\",\n  \"a\": 1\n}\n"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameEncrypted.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+				},
+			},
+			{
+				Config: config(`{"a":2,"b":"x"}`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionReplace),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					differentEncrypted.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+				},
+			},
+		},
+	})
+}
+
+func TestSopsEncryptResourceBinaryContent(t *testing.T) {
+	config := func(content string) string {
+		return fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "binary"
+					  content = %q
+					  age_public_keys = ["%s"]
+					}
+				`, content, agePubkey)
+	}
+	differentEncrypted := statecheck.CompareValue(compare.ValuesDiffer())
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("foo: bar\n"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					differentEncrypted.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+				},
+			},
+			{
+				// YAML-parsable binary content is still compared byte for byte.
+				Config: config("foo:   bar\n"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionReplace),
+					},
+				},
+				ConfigStateChecks: []statecheck.StateCheck{
+					differentEncrypted.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(encrypted string) error {
+					decrypted, err := SopsDecryptDataFromAgeKey(encrypted, "binary", agePrivkey)
+					if err != nil {
+						return err
+					}
+					assert.Equal(t, "foo:   bar\n", decrypted)
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestSopsEncryptResourceMovedCommentMarker(t *testing.T) {
+	config := func(content string) string {
+		return fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = %q
+					  encrypted_comment_regex = "sopsage:encrypted"
+					  age_public_keys = ["%s"]
+					}
+				`, content, agePubkey)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("# sopsage:encrypted\na: b\nc: d\n"),
+				Check:  resource.TestCheckResourceAttr("sopsage_encrypted_data.test", "encrypted_paths.0", `["a"]`),
+			},
+			{
+				// Moving the marker to another key changes what is encrypted.
+				Config: config("a: b\n# sopsage:encrypted\nc: d\n"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionReplace),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("sopsage_encrypted_data.test", "encrypted_paths.#", "1"),
+					resource.TestCheckResourceAttr("sopsage_encrypted_data.test", "encrypted_paths.0", `["c"]`),
+					resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(encrypted string) error {
+						assert.Contains(t, encrypted, "a: b\n")
+						assert.NotContains(t, encrypted, "c: d")
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
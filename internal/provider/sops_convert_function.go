@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &sopsConvertFunction{}
+)
+
+// NewSopsConvertFunction is a helper function to simplify the provider implementation.
+func NewSopsConvertFunction() function.Function {
+	return &sopsConvertFunction{}
+}
+
+// sopsConvertFunction is the function implementation.
+type sopsConvertFunction struct {
+}
+
+// Metadata returns the function name.
+func (f *sopsConvertFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "sops_convert"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *sopsConvertFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Converts an encrypted SOPS document to another format, without decrypting it.",
+		Description: "Loads an encrypted SOPS document and emits it in another format. The encrypted values and the MAC do " +
+			"not depend on the serialization, so no identity is needed and the converted document decrypts to the same " +
+			"values. Comments are dropped by the formats that cannot hold them, nested values cannot be converted to " +
+			"dotenv, and only binary documents can be converted to binary.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "encrypted",
+				Description: "The encrypted SOPS document.",
+			},
+			function.StringParameter{
+				Name:        "from",
+				Description: "The format of the encrypted document (json, yaml, etc.).",
+			},
+			function.StringParameter{
+				Name:        "to",
+				Description: "The format to convert the document to (json, yaml, etc.).",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run converts the document.
+func (f *sopsConvertFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var encrypted string
+	var from string
+	var to string
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &encrypted, &from, &to))
+	if resp.Error != nil {
+		return
+	}
+
+	converted, err := SopsConvertData(encrypted, from, to, nil)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewFuncError(fmt.Sprintf("Could not convert content: %s", err)))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, converted))
+}
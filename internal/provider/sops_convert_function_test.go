@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsConvertData(t *testing.T) {
+	encrypted, err := SopsEncryptDataFromAgeKeys("a:\n    b: c\nlist:\n    - 1\n", "yaml", []string{agePubkey}, &EncryptionConfig{EncryptedRegex: "^b$"})
+	require.NoError(t, err)
+
+	converted, err := SopsConvertData(encrypted, "yaml", "json", nil)
+	require.NoError(t, err)
+	decrypted, err := SopsDecryptDataFromAgeKey(converted, "json", agePrivkey)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"a": {"b": "c"}, "list": [1]}`, decrypted)
+	metadata, err := SopsLoadMetadata(converted, "json")
+	require.NoError(t, err)
+	assert.Equal(t, "^b$", metadata.EncryptedRegex)
+
+	roundTrip, err := SopsConvertData(converted, "json", "yaml", nil)
+	require.NoError(t, err)
+	assert.Equal(t, encrypted, roundTrip)
+
+	_, err = SopsConvertData(encrypted, "yaml", "dotenv", nil)
+	assert.Error(t, err)
+
+	multiDocument, err := SopsEncryptDataFromAgeKeys("a: b\n---\nc: d\n", "yaml", []string{agePubkey}, nil)
+	require.NoError(t, err)
+	_, err = SopsConvertData(multiDocument, "yaml", "json", nil)
+	assert.ErrorContains(t, err, "cannot hold the 2 documents")
+}
+
+func TestSopsConvertFunction(t *testing.T) {
+	encrypted, err := SopsEncryptDataFromAgeKeys("{\"foo\": \"bar\"}", "json", []string{agePubkey}, nil)
+	require.NoError(t, err)
+	expected, err := SopsConvertData(encrypted, "json", "yaml", nil)
+	require.NoError(t, err)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: `
+					output "test" {
+					  value = provider::sopsage::sops_convert("not sops", "json", "yaml")
+					}`,
+				ExpectError: regexp.MustCompile(`Could not convert\s+content`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::sops_convert(%q, "json", "yaml")
+					}`, encrypted),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(expected)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &sopsDecryptFileDataSource{}
+	_ datasource.DataSourceWithConfigure = &sopsDecryptFileDataSource{}
+)
+
+// NewSopsDecryptFileDataSource is a helper function to simplify the provider implementation.
+func NewSopsDecryptFileDataSource() datasource.DataSource {
+	return &sopsDecryptFileDataSource{}
+}
+
+// sopsDecryptFileDataSource is the data source implementation.
+type sopsDecryptFileDataSource struct {
+	providerData *sopsAgeProviderData
+}
+
+// sopsDecryptFileDataSourceModel maps the data source schema data.
+type sopsDecryptFileDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Filename       types.String `tfsdk:"filename"`
+	Format         types.String `tfsdk:"format"`
+	AgePrivateKeys types.List   `tfsdk:"age_private_keys"`
+	Content        types.String `tfsdk:"content"`
+}
+
+// Configure adds the provider data to the data source.
+func (d *sopsDecryptFileDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	d.providerData = providerData
+}
+
+// Metadata returns the data source type name.
+func (d *sopsDecryptFileDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decrypted_file"
+}
+
+// Schema defines the schema for the data source.
+func (d *sopsDecryptFileDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads and decrypts a SOPS file. The decrypted content is stored in the Terraform state, " +
+			"use the ephemeral resource of the same name to avoid it.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the data source, the path of the file.",
+				Computed:    true,
+			},
+			"filename": schema.StringAttribute{
+				Description: "The path of the SOPS file to decrypt.",
+				Required:    true,
+			},
+			"format": schema.StringAttribute{
+				Description: "The format of the file (json, yaml, etc.), inferred from the file extension like the sops CLI when omitted.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"age_private_keys": schema.ListAttribute{
+				Description: "List of age private keys to decrypt with, defaults to the identities of the provider age key files.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"content": schema.StringAttribute{
+				Description: "The decrypted content.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *sopsDecryptFileDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state sopsDecryptFileDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var agePrivateKeys []string
+	diags = state.AgePrivateKeys.ElementsAs(ctx, &agePrivateKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := state.Filename.ValueString()
+	format := state.Format.ValueString()
+	if format == "" {
+		format = SopsFormatForPath(filename)
+	}
+
+	content, err := SopsDecryptFile(filename, format, &DecryptionConfig{
+		AgePrivateKey: d.providerData.agePrivateKey(agePrivateKeys),
+		KeyServices:   d.providerData.keyServiceConfig(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Decrypting File",
+			fmt.Sprintf("Could not decrypt %s: %s", filename, err),
+		)
+		return
+	}
+
+	state.ID = types.StringValue(filename)
+	state.Format = types.StringValue(format)
+	state.Content = types.StringValue(content)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
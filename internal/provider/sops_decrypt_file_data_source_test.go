@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSopsDecryptFileDataSource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.enc.yaml")
+	encrypted, err := SopsEncryptDataFromAgeKeys("foo: bar\n", "yaml", []string{agePubkey}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filename, []byte(encrypted), 0o600))
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					data "sopsage_decrypted_file" "test" {
+					  filename = "%s"
+					  age_private_keys = ["%s", "%s"]
+					}`, filename, otherAgePrivkey, agePrivkey),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sopsage_decrypted_file.test",
+						tfjsonpath.New("format"),
+						knownvalue.StringExact("yaml"),
+					),
+					statecheck.ExpectKnownValue(
+						"data.sopsage_decrypted_file.test",
+						tfjsonpath.New("content"),
+						knownvalue.StringExact("foo: bar\n"),
+					),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource              = &sopsDecryptFileEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure = &sopsDecryptFileEphemeralResource{}
+)
+
+// NewSopsDecryptFileEphemeralResource is a helper function to simplify the provider implementation.
+func NewSopsDecryptFileEphemeralResource() ephemeral.EphemeralResource {
+	return &sopsDecryptFileEphemeralResource{}
+}
+
+// sopsDecryptFileEphemeralResource is the ephemeral resource implementation.
+type sopsDecryptFileEphemeralResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// sopsDecryptFileEphemeralResourceModel maps the ephemeral resource schema data.
+type sopsDecryptFileEphemeralResourceModel struct {
+	Filename       types.String `tfsdk:"filename"`
+	Format         types.String `tfsdk:"format"`
+	AgePrivateKeys types.List   `tfsdk:"age_private_keys"`
+	Content        types.String `tfsdk:"content"`
+}
+
+// Configure adds the provider data to the ephemeral resource.
+func (e *sopsDecryptFileEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	e.providerData = providerData
+}
+
+// Metadata returns the ephemeral resource type name.
+func (e *sopsDecryptFileEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decrypted_file"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (e *sopsDecryptFileEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads and decrypts a SOPS file without storing the decrypted content in the Terraform state.",
+		Attributes: map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Description: "The path of the SOPS file to decrypt.",
+				Required:    true,
+			},
+			"format": schema.StringAttribute{
+				Description: "The format of the file (json, yaml, etc.), inferred from the file extension like the sops CLI when omitted.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"age_private_keys": schema.ListAttribute{
+				Description: "List of age private keys to decrypt with, defaults to the identities of the provider age key files.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"content": schema.StringAttribute{
+				Description: "The decrypted content.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// Open decrypts the file.
+func (e *sopsDecryptFileEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data sopsDecryptFileEphemeralResourceModel
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var agePrivateKeys []string
+	diags = data.AgePrivateKeys.ElementsAs(ctx, &agePrivateKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := data.Filename.ValueString()
+	format := data.Format.ValueString()
+	if format == "" {
+		format = SopsFormatForPath(filename)
+	}
+
+	content, err := SopsDecryptFile(filename, format, &DecryptionConfig{
+		AgePrivateKey: e.providerData.agePrivateKey(agePrivateKeys),
+		KeyServices:   e.providerData.keyServiceConfig(),
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Decrypting File",
+			fmt.Sprintf("Could not decrypt %s: %s", filename, err),
+		)
+		return
+	}
+
+	data.Format = types.StringValue(format)
+	data.Content = types.StringValue(content)
+
+	diags = resp.Result.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
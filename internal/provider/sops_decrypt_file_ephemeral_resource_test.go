@@ -0,0 +1,45 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSopsDecryptFileEphemeralResource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.enc.json")
+	encrypted, err := SopsEncryptDataFromAgeKeys(`{"foo": "bar"}`, "json", []string{agePubkey}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filename, []byte(encrypted), 0o600))
+
+	config := func(agePrivateKey string) string {
+		return fmt.Sprintf(`
+					ephemeral "sopsage_decrypted_file" "test" {
+					  filename = "%s"
+					  age_private_keys = ["%s"]
+					}`, filename, agePrivateKey)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config(agePrivkey),
+			},
+			{
+				Config:      config(otherAgePrivkey),
+				ExpectError: regexp.MustCompile("Error Decrypting File"),
+			},
+		},
+	})
+}
@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/ephemeralvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ ephemeral.EphemeralResource                     = &sopsDecryptTempfileEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure        = &sopsDecryptTempfileEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigValidators = &sopsDecryptTempfileEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithClose            = &sopsDecryptTempfileEphemeralResource{}
+)
+
+// tempfileDirectoryKey is the private data key recording the directory holding the decrypted file.
+const tempfileDirectoryKey = "directory"
+
+// NewSopsDecryptTempfileEphemeralResource is a helper function to simplify the provider implementation.
+func NewSopsDecryptTempfileEphemeralResource() ephemeral.EphemeralResource {
+	return &sopsDecryptTempfileEphemeralResource{}
+}
+
+// sopsDecryptTempfileEphemeralResource is the ephemeral resource implementation.
+type sopsDecryptTempfileEphemeralResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// sopsDecryptTempfileEphemeralResourceModel maps the ephemeral resource schema data.
+type sopsDecryptTempfileEphemeralResourceModel struct {
+	Filename       types.String `tfsdk:"filename"`
+	Encrypted      types.String `tfsdk:"encrypted"`
+	Format         types.String `tfsdk:"format"`
+	AgePrivateKeys types.List   `tfsdk:"age_private_keys"`
+	Path           types.String `tfsdk:"path"`
+}
+
+// Configure adds the provider data to the ephemeral resource.
+func (e *sopsDecryptTempfileEphemeralResource) Configure(_ context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	e.providerData = providerData
+}
+
+// Metadata returns the ephemeral resource type name.
+func (e *sopsDecryptTempfileEphemeralResource) Metadata(_ context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_decrypted_tempfile"
+}
+
+// Schema defines the schema for the ephemeral resource.
+func (e *sopsDecryptTempfileEphemeralResource) Schema(_ context.Context, _ ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Decrypts a SOPS document into a temporary file readable only by the current user, for tools expecting " +
+			"a path such as helm or kubectl. The file lives in a private directory and is overwritten and removed when " +
+			"Terraform closes the ephemeral resource, at the end of the plan or apply.",
+		Attributes: map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Description: "The path of the SOPS file to decrypt. Exactly one of filename or encrypted must be set.",
+				Optional:    true,
+			},
+			"encrypted": schema.StringAttribute{
+				Description: "The SOPS encrypted content to decrypt. Exactly one of filename or encrypted must be set.",
+				Optional:    true,
+			},
+			"format": schema.StringAttribute{
+				Description: "The format of the document (json, yaml, dotenv, ini or binary). Inferred from the extension of " +
+					"filename like the sops CLI when omitted, required with encrypted. The temporary file has the extension of the format.",
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("json", "yaml", "dotenv", "ini", "binary"),
+				},
+			},
+			"age_private_keys": schema.ListAttribute{
+				Description: "List of age private keys to decrypt with, defaults to the identities of the provider age key files.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+			},
+			"path": schema.StringAttribute{
+				Description: "The path of the temporary file holding the decrypted content.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+// ConfigValidators requires exactly one source of encrypted content.
+func (e *sopsDecryptTempfileEphemeralResource) ConfigValidators(_ context.Context) []ephemeral.ConfigValidator {
+	return []ephemeral.ConfigValidator{
+		ephemeralvalidator.ExactlyOneOf(
+			path.MatchRoot("filename"),
+			path.MatchRoot("encrypted"),
+		),
+	}
+}
+
+// Open decrypts the document into a temporary file.
+func (e *sopsDecryptTempfileEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data sopsDecryptTempfileEphemeralResourceModel
+	diags := req.Config.Get(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var agePrivateKeys []string
+	diags = data.AgePrivateKeys.ElementsAs(ctx, &agePrivateKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	format := data.Format.ValueString()
+	if format == "" {
+		if data.Filename.IsNull() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("format"),
+				"Missing Format",
+				"format must be set to decrypt encrypted content.",
+			)
+			return
+		}
+		format = SopsFormatForPath(data.Filename.ValueString())
+	}
+
+	decryptionConfig := &DecryptionConfig{
+		AgePrivateKey: e.providerData.agePrivateKey(agePrivateKeys),
+		KeyServices:   e.providerData.keyServiceConfig(),
+	}
+	var content string
+	var err error
+	if data.Filename.IsNull() {
+		content, err = SopsDecryptData(data.Encrypted.ValueString(), format, decryptionConfig)
+	} else {
+		content, err = SopsDecryptFile(data.Filename.ValueString(), format, decryptionConfig)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Decrypting Content",
+			fmt.Sprintf("Could not decrypt content: %s", err),
+		)
+		return
+	}
+
+	directory, err := os.MkdirTemp("", "sopsage-")
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Writing Temporary File",
+			fmt.Sprintf("Could not create temporary directory: %s", err),
+		)
+		return
+	}
+	filename := filepath.Join(directory, "decrypted"+tempfileExtension(format))
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Writing Temporary File",
+			fmt.Sprintf("Could not write %s: %s", filename, err),
+		)
+		_ = removeTempDirectory(directory)
+		return
+	}
+
+	// Terraform only closes ephemeral resources opened successfully, so remove the directory here on failure.
+	directoryJSON, err := json.Marshal(directory)
+	if err == nil {
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, tempfileDirectoryKey, directoryJSON)...)
+	} else {
+		resp.Diagnostics.AddError(
+			"Error Writing Temporary File",
+			fmt.Sprintf("Could not record temporary directory: %s", err),
+		)
+	}
+	if resp.Diagnostics.HasError() {
+		_ = removeTempDirectory(directory)
+		return
+	}
+
+	data.Format = types.StringValue(format)
+	data.Path = types.StringValue(filename)
+
+	diags = resp.Result.Set(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		_ = removeTempDirectory(directory)
+		return
+	}
+}
+
+// Close overwrites and removes the temporary file with its directory.
+func (e *sopsDecryptTempfileEphemeralResource) Close(ctx context.Context, req ephemeral.CloseRequest, resp *ephemeral.CloseResponse) {
+	directoryJSON, diags := req.Private.GetKey(ctx, tempfileDirectoryKey)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() || directoryJSON == nil {
+		return
+	}
+
+	var directory string
+	if err := json.Unmarshal(directoryJSON, &directory); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Removing Temporary File",
+			fmt.Sprintf("Could not read temporary directory: %s", err),
+		)
+		return
+	}
+
+	if err := removeTempDirectory(directory); err != nil {
+		resp.Diagnostics.AddError(
+			"Error Removing Temporary File",
+			fmt.Sprintf("Could not remove %s: %s", directory, err),
+		)
+		return
+	}
+}
+
+// removeTempDirectory overwrites the regular files of a directory with zeros before removing the directory. Overwriting
+// does not guarantee the plaintext is gone from copy-on-write or journaling file systems, but keeps it out of reach of
+// anyone opening the file before its removal.
+func removeTempDirectory(directory string) error {
+	entries, err := os.ReadDir(directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			if err := overwriteFile(filepath.Join(directory, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return os.RemoveAll(directory)
+}
+
+// overwriteFile overwrites a file with zeros, syncing them to disk.
+func overwriteFile(filename string) error {
+	file, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(make([]byte, info.Size())); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// tempfileExtension returns the file extension of a format, so that tools can infer it from the temporary file.
+func tempfileExtension(format string) string {
+	switch format {
+	case "yaml", "json", "ini":
+		return "." + format
+	case "dotenv":
+		return ".env"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,115 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsDecryptTempfileEphemeralResource(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("TMPDIR", tmpDir)
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+
+	// The age key file of an aliased provider is decrypted into the temporary file, which the provider then reads.
+	keyFile := filepath.Join(t.TempDir(), "keys.txt.enc")
+	encryptedKey, err := SopsEncryptDataFromAgeKeys(otherAgePrivkey+"\n", "binary", []string{agePubkey}, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, []byte(encryptedKey), 0o600))
+	secretFile := filepath.Join(t.TempDir(), "secret.enc.yaml")
+	encryptedSecret, err := SopsEncryptDataFromAgeKeys("foo: bar\n", "yaml", []string{otherAgePubkey}, nil)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(secretFile, []byte(encryptedSecret), 0o600))
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					ephemeral "sopsage_decrypted_tempfile" "test" {
+					  encrypted = <<-EOT
+%s
+					  EOT
+					}`, encryptedSecret),
+				ExpectError: regexp.MustCompile("Missing Format"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					ephemeral "sopsage_decrypted_tempfile" "test" {
+					  filename = "%s"
+					  age_private_keys = ["%s"]
+					}`, secretFile, agePrivkey),
+				ExpectError: regexp.MustCompile("Error Decrypting Content"),
+			},
+			{
+				Config: fmt.Sprintf(`
+					ephemeral "sopsage_decrypted_tempfile" "keys" {
+					  filename = "%s"
+					  age_private_keys = ["%s"]
+					}
+
+					provider "sopsage" {
+					  alias                  = "tempfile"
+					  environment_identities = "ignore"
+					  age_key_file           = ephemeral.sopsage_decrypted_tempfile.keys.path
+					}
+
+					data "sopsage_decrypted_file" "test" {
+					  provider = sopsage.tempfile
+					  filename = "%s"
+					}`, keyFile, agePrivkey, secretFile),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"data.sopsage_decrypted_file.test",
+						tfjsonpath.New("content"),
+						knownvalue.StringExact("foo: bar\n"),
+					),
+				},
+			},
+		},
+	})
+
+	// Every temporary directory was removed on close.
+	entries, err := os.ReadDir(tmpDir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotRegexp(t, "^sopsage-", entry.Name())
+	}
+}
+
+func TestRemoveTempDirectory(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "sopsage-test")
+	require.NoError(t, os.Mkdir(directory, 0o700))
+	filename := filepath.Join(directory, "decrypted.yaml")
+	require.NoError(t, os.WriteFile(filename, []byte("foo: bar\n"), 0o600))
+
+	// Keep the file open to read what the overwrite left behind the removal.
+	file, err := os.Open(filename)
+	require.NoError(t, err)
+	defer file.Close()
+
+	require.NoError(t, removeTempDirectory(directory))
+	assert.NoDirExists(t, directory)
+	content := make([]byte, 9)
+	_, err = file.Read(content)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 9), content)
+
+	// Removing twice is not an error.
+	assert.NoError(t, removeTempDirectory(directory))
+}
@@ -0,0 +1,320 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/getsops/sops/v3"
+	sopsaes "github.com/getsops/sops/v3/aes"
+	keysource "github.com/getsops/sops/v3/age"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Deterministic mode is experimental. It derives every secret SOPS and age would normally draw at random from a caller provided seed and
+// the content, so that encrypting the same content twice gives the same document. Anyone knowing the seed can derive
+// the data key of any content, and equal content is visible as equal ciphertext.
+
+// deterministicLastModified is recorded as the last modification time of deterministic documents.
+var deterministicLastModified = time.Unix(0, 0).UTC()
+
+// minDeterministicSeedSize is the minimum size of a deterministic seed, in bytes.
+const minDeterministicSeedSize = 32
+
+// deterministicSecrets holds the secrets of a deterministic document.
+type deterministicSecrets struct {
+	dataKey []byte
+	ivKey   []byte
+}
+
+// newDeterministicSecrets derives the data key and the IV key of a document from the seed, the format and the content.
+func newDeterministicSecrets(seed []byte, format string, content string) (*deterministicSecrets, error) {
+	if len(seed) < minDeterministicSeedSize {
+		return nil, fmt.Errorf("the deterministic seed must be at least %d bytes long", minDeterministicSeedSize)
+	}
+	salt := sha256.Sum256([]byte(format + "\x00" + content))
+	dataKey, err := hkdf.Key(sha256.New, seed, salt[:], "sopsage deterministic data key", 32)
+	if err != nil {
+		return nil, err
+	}
+	ivKey, err := hkdf.Key(sha256.New, seed, salt[:], "sopsage deterministic iv", 32)
+	if err != nil {
+		return nil, err
+	}
+	return &deterministicSecrets{dataKey: dataKey, ivKey: ivKey}, nil
+}
+
+// checkDeterministicConfig rejects the settings deterministic mode cannot honour, rather than ignoring them.
+func checkDeterministicConfig(encryptionConfig *EncryptionConfig) error {
+	var unsupported []string
+	if len(encryptionConfig.PgpPublicKeys) > 0 {
+		unsupported = append(unsupported, "PGP public keys")
+	}
+	if len(encryptionConfig.VaultTransitKeys) > 0 {
+		unsupported = append(unsupported, "Vault transit keys")
+	}
+	if encryptionConfig.KeyServices != nil {
+		unsupported = append(unsupported, "key services")
+	}
+	if encryptionConfig.DataKey != nil {
+		unsupported = append(unsupported, "a given data key")
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("deterministic mode only supports X25519 age public keys and a derived data key, got %s",
+			strings.Join(unsupported, ", "))
+	}
+	return nil
+}
+
+// encryptTreeDeterministic encrypts tree for agePublicKeys like common.EncryptTree does, with the secrets derived from
+// seed and the content instead of random ones and a fixed last modification time.
+func encryptTreeDeterministic(tree *sops.Tree, seed []byte, format string, content string, agePublicKeys []string) error {
+	if len(agePublicKeys) == 0 {
+		return fmt.Errorf("deterministic mode requires at least one age public key")
+	}
+	secrets, err := newDeterministicSecrets(seed, format, content)
+	if err != nil {
+		return err
+	}
+	var keyGroup sops.KeyGroup
+	for _, recipient := range agePublicKeys {
+		masterKey, err := deterministicAgeMasterKey(strings.TrimSpace(recipient), secrets.dataKey)
+		if err != nil {
+			return err
+		}
+		keyGroup = append(keyGroup, masterKey)
+	}
+	tree.Metadata.KeyGroups = []sops.KeyGroup{keyGroup}
+
+	valueCipher := newDeterministicCipher(secrets.ivKey)
+	mac, err := tree.Encrypt(secrets.dataKey, valueCipher)
+	if err != nil {
+		return fmt.Errorf("error encrypting tree: %w", err)
+	}
+	tree.Metadata.LastModified = deterministicLastModified
+	tree.Metadata.MessageAuthenticationCode, err = valueCipher.Encrypt(mac, secrets.dataKey, deterministicLastModified.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("could not encrypt MAC: %w", err)
+	}
+	return nil
+}
+
+// deterministicCipher is a sops.Cipher producing the same AES256_GCM values as the sops cipher, with IVs derived from
+// the IV key, the additional data and the value instead of random ones.
+type deterministicCipher struct {
+	sopsaes.Cipher
+	ivKey []byte
+}
+
+func newDeterministicCipher(ivKey []byte) deterministicCipher {
+	return deterministicCipher{Cipher: sopsaes.NewCipher(), ivKey: ivKey}
+}
+
+// Encrypt encrypts a value like the sops cipher does.
+func (c deterministicCipher) Encrypt(plaintext interface{}, key []byte, additionalData string) (string, error) {
+	if isEmptySopsValue(plaintext) {
+		return "", nil
+	}
+	var plainBytes []byte
+	var encryptedType string
+	switch value := plaintext.(type) {
+	case string:
+		encryptedType = "str"
+		plainBytes = []byte(value)
+	case int:
+		encryptedType = "int"
+		plainBytes = []byte(strconv.Itoa(value))
+	case float64:
+		encryptedType = "float"
+		plainBytes = []byte(strconv.FormatFloat(value, 'f', -1, 64))
+	case bool:
+		encryptedType = "bool"
+		plainBytes = []byte("False")
+		if value {
+			plainBytes = []byte("True")
+		}
+	case time.Time:
+		encryptedType = "time"
+		var err error
+		plainBytes, err = value.MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("error marshaling timestamp %q: %w", value, err)
+		}
+	case sops.Comment:
+		encryptedType = "comment"
+		plainBytes = []byte(value.Value)
+	default:
+		return "", fmt.Errorf("value to encrypt has unsupported type %T", value)
+	}
+	mac := hmac.New(sha256.New, c.ivKey)
+	mac.Write([]byte(additionalData + "\x00" + encryptedType + "\x00"))
+	mac.Write(plainBytes)
+	iv := mac.Sum(nil)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", err
+	}
+	out := gcm.Seal(nil, iv, plainBytes, []byte(additionalData))
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]",
+		base64.StdEncoding.EncodeToString(out[:len(out)-gcm.Overhead()]),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(out[len(out)-gcm.Overhead():]),
+		encryptedType), nil
+}
+
+// isEmptySopsValue reports whether the sops cipher leaves a value empty instead of encrypting it.
+func isEmptySopsValue(value interface{}) bool {
+	switch value := value.(type) {
+	case string:
+		return value == ""
+	case sops.Comment:
+		return value.Value == ""
+	default:
+		return false
+	}
+}
+
+// deterministicAgeMasterKey returns the age master key of recipient with the data key encrypted deterministically. Only
+// X25519 age recipients are supported, the file key, ephemeral share and payload nonce are derived from the data key.
+// age draws these at random with no way to supply them, so the age v1 format is written here: an X25519 stanza, the
+// header MAC and a single STREAM chunk. Tests decrypt the result with age itself to keep it in line with age.
+func deterministicAgeMasterKey(recipient string, dataKey []byte) (*keysource.MasterKey, error) {
+	if _, err := age.ParseX25519Recipient(recipient); err != nil {
+		return nil, fmt.Errorf("deterministic mode only supports X25519 age public keys: %w", err)
+	}
+	theirPublicKey, err := bech32Data(recipient)
+	if err != nil {
+		return nil, err
+	}
+	derive := func(label string, size int) ([]byte, error) {
+		return hkdf.Key(sha256.New, dataKey, theirPublicKey, "sopsage deterministic age "+label, size)
+	}
+
+	fileKey, err := derive("file key", 16)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralScalar, err := derive("ephemeral", 32)
+	if err != nil {
+		return nil, err
+	}
+	payloadNonce, err := derive("payload nonce", 16)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wrap the file key for the recipient, as in the X25519 recipient of age.
+	ephemeral, err := ecdh.X25519().NewPrivateKey(ephemeralScalar)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := ecdh.X25519().NewPublicKey(theirPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := ephemeral.ECDH(remote)
+	if err != nil {
+		return nil, err
+	}
+	ourPublicKey := ephemeral.PublicKey().Bytes()
+	wrappingKey, err := hkdf.Key(sha256.New, sharedSecret, append(append([]byte{}, ourPublicKey...), theirPublicKey...), "age-encryption.org/v1/X25519", chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := chacha20poly1305Seal(wrappingKey, make([]byte, chacha20poly1305.NonceSize), fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write the header and its MAC.
+	b64 := base64.RawStdEncoding
+	var header bytes.Buffer
+	header.WriteString("age-encryption.org/v1\n")
+	header.WriteString("-> X25519 " + b64.EncodeToString(ourPublicKey) + "\n")
+	body := b64.EncodeToString(wrappedKey)
+	for len(body) >= 64 {
+		header.WriteString(body[:64] + "\n")
+		body = body[64:]
+	}
+	header.WriteString(body + "\n")
+	header.WriteString("---")
+	hmacKey, err := hkdf.Key(sha256.New, fileKey, nil, "header", 32)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(header.Bytes())
+	header.WriteString(" " + b64.EncodeToString(mac.Sum(nil)) + "\n")
+
+	// Encrypt the data key as the single, last chunk of the payload.
+	streamKey, err := hkdf.Key(sha256.New, fileKey, payloadNonce, "payload", chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	chunkNonce := make([]byte, chacha20poly1305.NonceSize)
+	chunkNonce[len(chunkNonce)-1] = 1
+	payload, err := chacha20poly1305Seal(streamKey, chunkNonce, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var encrypted bytes.Buffer
+	aw := armor.NewWriter(&encrypted)
+	for _, part := range [][]byte{header.Bytes(), payloadNonce, payload} {
+		if _, err := aw.Write(part); err != nil {
+			return nil, err
+		}
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return &keysource.MasterKey{Recipient: recipient, EncryptedKey: encrypted.String()}, nil
+}
+
+func chacha20poly1305Seal(key, nonce, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, nonce, plaintext, nil), nil
+}
+
+// bech32Data returns the data of a bech32 string whose checksum was already verified.
+func bech32Data(s string) ([]byte, error) {
+	const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	separator := strings.LastIndexByte(s, '1')
+	if separator < 1 || separator+7 > len(s) {
+		return nil, fmt.Errorf("invalid bech32 string")
+	}
+	var data []byte
+	var acc, bits uint
+	for _, c := range strings.ToLower(s[separator+1 : len(s)-6]) {
+		value := strings.IndexRune(charset, c)
+		if value < 0 {
+			return nil, fmt.Errorf("invalid bech32 character %q", c)
+		}
+		acc = acc<<5 | uint(value)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			data = append(data, byte(acc>>bits))
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	keysource "github.com/getsops/sops/v3/age"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsEncryptDataDeterministic(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	content := "foo: bar\nnumber: 42\nenabled: true\nlist:\n    - a\n    - a\n# comment\nempty: \"\"\n"
+	encrypt := func(content string, seed []byte, agePublicKeys ...string) (string, error) {
+		return SopsEncryptDataFromAgeKeys(content, "yaml", agePublicKeys, &EncryptionConfig{
+			UnencryptedSuffix: "_unencrypted",
+			DeterministicSeed: seed,
+		})
+	}
+
+	first, err := encrypt(content, seed, agePubkey, otherAgePubkey)
+	require.NoError(t, err)
+	second, err := encrypt(content, seed, agePubkey, otherAgePubkey)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Contains(t, first, "lastmodified: \"1970-01-01T00:00:00Z\"")
+
+	for _, agePrivateKey := range []string{agePrivkey, otherAgePrivkey} {
+		decrypted, err := SopsDecryptDataFromAgeKey(first, "yaml", agePrivateKey)
+		require.NoError(t, err)
+		assert.Equal(t, content, decrypted)
+	}
+
+	changed, err := encrypt(content+"other: value\n", seed, agePubkey, otherAgePubkey)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, changed)
+	otherSeed, err := encrypt(content, []byte("fedcba9876543210fedcba9876543210"), agePubkey, otherAgePubkey)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, otherSeed)
+
+	_, err = encrypt(content, []byte("short"), agePubkey)
+	assert.ErrorContains(t, err, "at least 32 bytes")
+	_, err = encrypt(content, seed, sshPubkey)
+	assert.ErrorContains(t, err, "only supports X25519 age public keys")
+	for name, encryptionConfig := range map[string]*EncryptionConfig{
+		"a given data key":   {DeterministicSeed: seed, DataKey: make([]byte, 32)},
+		"key services":       {DeterministicSeed: seed, KeyServices: &KeyServiceConfig{}},
+		"PGP public keys":    {DeterministicSeed: seed, PgpPublicKeys: []string{"key"}},
+		"Vault transit keys": {DeterministicSeed: seed, VaultTransitKeys: []VaultTransitKey{{}}},
+	} {
+		_, err = SopsEncryptDataFromAgeKeys(content, "yaml", []string{agePubkey}, encryptionConfig)
+		assert.ErrorContains(t, err, "got "+name)
+	}
+}
+
+func TestSopsEncryptDataDeterministicAgeFormat(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	content := "foo: bar\n"
+	encrypted, err := SopsEncryptDataFromAgeKeys(content, "yaml", []string{agePubkey, otherAgePubkey}, &EncryptionConfig{
+		DeterministicSeed: seed,
+	})
+	require.NoError(t, err)
+	secrets, err := newDeterministicSecrets(seed, "yaml", content)
+	require.NoError(t, err)
+
+	// Decrypt the data key with age itself, not through the sops key source, to check the hand-written age format.
+	metadata, err := SopsLoadMetadata(encrypted, "yaml")
+	require.NoError(t, err)
+	require.Len(t, metadata.KeyGroups, 1)
+	require.Len(t, metadata.KeyGroups[0], 2)
+	for i, agePrivateKey := range []string{agePrivkey, otherAgePrivkey} {
+		masterKey, ok := metadata.KeyGroups[0][i].(*keysource.MasterKey)
+		require.True(t, ok)
+		identity, err := age.ParseX25519Identity(agePrivateKey)
+		require.NoError(t, err)
+		reader, err := age.Decrypt(armor.NewReader(strings.NewReader(masterKey.EncryptedKey)), identity)
+		require.NoError(t, err)
+		dataKey, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, secrets.dataKey, dataKey)
+	}
+}
+
+func TestSopsEncryptDeterministicFunction(t *testing.T) {
+	seed := "0123456789abcdef0123456789abcdef"
+	expected, err := SopsEncryptDataFromAgeKeys("{\"foo\": \"bar\"}", "json", []string{agePubkey}, &EncryptionConfig{
+		UnencryptedSuffix: "_unencrypted",
+		DeterministicSeed: []byte(seed),
+	})
+	require.NoError(t, err)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::sops_encrypt_deterministic("{\"foo\": \"bar\"}", "json", ["%s"], "%s", true, false)
+					}`, agePubkey, seed),
+				ExpectError: regexp.MustCompile(`Too many function arguments`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::sops_encrypt_deterministic("{\"foo\": \"bar\"}", "json", ["%s"], "%s")
+					}`, agePubkey, seed),
+				ExpectError: regexp.MustCompile(`Not enough function arguments`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::sops_encrypt_deterministic("{\"foo\": \"bar\"}", "json", ["%s"], "short", null)
+					}`, agePubkey),
+				ExpectError: regexp.MustCompile(`Could not encrypt\s+content`),
+			},
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::sops_encrypt_deterministic("{\"foo\": \"bar\"}", "json", ["%s"], "%s", null)
+					}`, agePubkey, seed),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(expected)),
+				},
+			},
+		},
+	})
+}
+
+func TestSopsEncryptDeterministicFunctionMACOnlyEncrypted(t *testing.T) {
+	seed := "0123456789abcdef0123456789abcdef"
+	content := "{\"password\": \"secret\", \"name_unencrypted\": \"app\"}"
+	expected, err := SopsEncryptDataFromAgeKeys(content, "json", []string{agePubkey}, &EncryptionConfig{
+		DeterministicSeed: []byte(seed),
+		MACOnlyEncrypted:  true,
+	})
+	require.NoError(t, err)
+
+	metadata, err := SopsLoadMetadata(expected, "json")
+	require.NoError(t, err)
+	assert.True(t, metadata.MACOnlyEncrypted)
+	// The MAC does not cover cleartext values, so editing them keeps the document valid.
+	edited := strings.Replace(expected, `"name_unencrypted": "app"`, `"name_unencrypted": "other"`, 1)
+	require.NotEqual(t, expected, edited)
+	decrypted, err := SopsDecryptDataFromAgeKey(edited, "json", agePrivkey)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"password": "secret", "name_unencrypted": "other"}`, decrypted)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					output "test" {
+					  value = provider::sopsage::sops_encrypt_deterministic(%q, "json", ["%s"], "%s", true)
+					}`, content, agePubkey, seed),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.StringExact(expected)),
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ function.Function = &sopsEncryptDeterministicFunction{}
+)
+
+// NewSopsEncryptDeterministicFunction is a helper function to simplify the provider implementation.
+func NewSopsEncryptDeterministicFunction() function.Function {
+	return &sopsEncryptDeterministicFunction{}
+}
+
+// sopsEncryptDeterministicFunction is the function implementation.
+type sopsEncryptDeterministicFunction struct {
+}
+
+// Metadata returns the function name.
+func (f *sopsEncryptDeterministicFunction) Metadata(_ context.Context, _ function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "sops_encrypt_deterministic"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *sopsEncryptDeterministicFunction) Definition(_ context.Context, _ function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Experimental: encrypts content with SOPS deterministically, for plan-stable output.",
+		Description: "EXPERIMENTAL: the output may change in a future release. Encrypts content with SOPS for the " +
+			"given age public keys, deriving the data key, the IVs and the age encryption of the data key from the seed " +
+			"and the content with HKDF, so the same inputs always give the same document. WARNING: anyone knowing the " +
+			"seed can derive the data key of any content, and equal values are visible as equal ciphertext. Keep the " +
+			"seed secret and random, and never reuse it outside of this function. Only X25519 age public keys are " +
+			"supported, the provider key services are not used, and lastmodified is fixed to the Unix epoch.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:        "content",
+				Description: "The content to encrypt.",
+			},
+			function.StringParameter{
+				Name:        "format",
+				Description: "The format of the content (json, yaml, etc.).",
+			},
+			function.ListParameter{
+				Name:        "age_public_keys",
+				Description: "List of X25519 age public keys to encrypt with.",
+				ElementType: types.StringType,
+			},
+			function.StringParameter{
+				Name:        "seed",
+				Description: "Secret seed of at least 32 bytes the encryption is derived from.",
+			},
+			function.BoolParameter{
+				Name:           "mac_only_encrypted",
+				Description:    "Compute the MAC over the encrypted values only, leaving cleartext values free to change. Null means false.",
+				AllowNullValue: true,
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run encrypts the content.
+func (f *sopsEncryptDeterministicFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var content string
+	var format string
+	var agePublicKeys []string
+	var seed string
+	var macOnlyEncrypted types.Bool
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, req.Arguments.Get(ctx, &content, &format, &agePublicKeys, &seed, &macOnlyEncrypted))
+	if resp.Error != nil {
+		return
+	}
+
+	encryptionConfig := DefaultEncryptionConfig()
+	encryptionConfig.DeterministicSeed = []byte(seed)
+	encryptionConfig.MACOnlyEncrypted = macOnlyEncrypted.ValueBool()
+	encrypted, err := SopsEncryptDataFromAgeKeys(content, format, agePublicKeys, encryptionConfig)
+	if err != nil {
+		resp.Error = function.ConcatFuncErrors(resp.Error, function.NewFuncError(fmt.Sprintf("Could not encrypt content: %s", err)))
+		return
+	}
+
+	resp.Error = function.ConcatFuncErrors(resp.Error, resp.Result.Set(ctx, encrypted))
+}
@@ -0,0 +1,335 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                     = &sopsEncryptFileResource{}
+	_ resource.ResourceWithConfigure        = &sopsEncryptFileResource{}
+	_ resource.ResourceWithValidateConfig   = &sopsEncryptFileResource{}
+	_ resource.ResourceWithConfigValidators = &sopsEncryptFileResource{}
+)
+
+// NewSopsEncryptFileResource is a helper function to simplify the provider implementation.
+func NewSopsEncryptFileResource() resource.Resource {
+	return &sopsEncryptFileResource{}
+}
+
+// sopsEncryptFileResource is the resource implementation.
+type sopsEncryptFileResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// sopsEncryptFileResourceModel maps the resource schema data.
+type sopsEncryptFileResourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	Filename       types.String `tfsdk:"filename"`
+	FilePermission types.String `tfsdk:"file_permission"`
+	Content        types.String `tfsdk:"content"`
+	Format         types.String `tfsdk:"format"`
+	AgePublicKeys  types.List   `tfsdk:"age_public_keys"`
+	sopsEncryptionRulesModel
+	Encrypted types.String `tfsdk:"encrypted"`
+}
+
+// Configure adds the provider data to the resource.
+func (r *sopsEncryptFileResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.providerData = providerData
+}
+
+// Metadata returns the resource type name.
+func (r *sopsEncryptFileResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_encrypted_file"
+}
+
+// Schema defines the schema for the resource.
+func (r *sopsEncryptFileResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Description: "Identifier for the resource, the path of the file.",
+			Computed:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+		"filename": schema.StringAttribute{
+			Description: "The path of the file to write. Missing parent directories are created.",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"file_permission": schema.StringAttribute{
+			Description:   "Permissions of the file in octal notation, defaults to \"0600\".",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Default:       stringdefault.StaticString("0600"),
+		},
+		"content": schema.StringAttribute{
+			Description: "The content to encrypt.",
+			Required:    true,
+			Sensitive:   true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"format": schema.StringAttribute{
+			Description: "The format of the content (json, yaml, etc.).",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+		},
+		"age_public_keys": schema.ListAttribute{
+			Description: "List of age public keys to encrypt with.",
+			Required:    true,
+			ElementType: types.StringType,
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.RequiresReplace(),
+			},
+		},
+		"encrypted": schema.StringAttribute{
+			Description: "The encrypted content in SOPS format, as written to the file.",
+			Computed:    true,
+			Sensitive:   false,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
+			},
+		},
+	}
+	maps.Copy(attributes, sopsEncryptionRulesAttributes())
+
+	resp.Schema = schema.Schema{
+		Description: "Encrypts content using SOPS with age encryption and writes it to a local file. " +
+			"The file is recreated when it is deleted, edited or its permissions change outside of Terraform.",
+		Attributes: attributes,
+	}
+}
+
+// ConfigValidators rejects conflicting encryption rules.
+func (r *sopsEncryptFileResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return sopsEncryptionRulesConfigValidators()
+}
+
+// ValidateConfig validates the file permission.
+func (r *sopsEncryptFileResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config sopsEncryptFileResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.FilePermission.IsNull() || config.FilePermission.IsUnknown() {
+		return
+	}
+	if _, err := parseFilePermission(config.FilePermission.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("file_permission"),
+			"Invalid File Permission",
+			err.Error(),
+		)
+	}
+}
+
+// Create encrypts the content and writes the file.
+func (r *sopsEncryptFileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan sopsEncryptFileResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Get the age public keys
+	var agePublicKeys []string
+	diags = plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	encryptionConfig := plan.EncryptionConfig()
+	encryptionConfig.KeyServices = r.providerData.keyServiceConfig()
+	encryptionConfig.Stores = r.providerData.storesConfig()
+
+	// Encrypt the content
+	encrypted, err := SopsEncryptDataFromAgeKeys(plan.Content.ValueString(), plan.Format.ValueString(), agePublicKeys, encryptionConfig)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Encrypting Content",
+			fmt.Sprintf("Could not encrypt content: %s", err),
+		)
+		return
+	}
+
+	// Write the file
+	filename := plan.Filename.ValueString()
+	perm, err := parseFilePermission(plan.FilePermission.ValueString())
+	if err == nil {
+		err = writeFileAtomic(filename, []byte(encrypted), perm)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Writing File",
+			fmt.Sprintf("Could not write %s: %s", filename, err),
+		)
+		return
+	}
+
+	// Set resource ID
+	plan.ID = types.StringValue(filename)
+	// Set encrypted content
+	plan.Encrypted = types.StringValue(encrypted)
+
+	// Set state to computed values
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read removes the resource from the state when the file is gone, or its content or permissions drifted, so it gets
+// recreated.
+func (r *sopsEncryptFileResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state sopsEncryptFileResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := state.Filename.ValueString()
+	info, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	var onDisk []byte
+	if err == nil {
+		onDisk, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading File",
+			fmt.Sprintf("Could not read %s: %s", filename, err),
+		)
+		return
+	}
+
+	if string(onDisk) != state.Encrypted.ValueString() {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	// Windows only reports whether a file is read-only.
+	perm, err := parseFilePermission(state.FilePermission.ValueString())
+	if err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != perm {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update records attributes added to the schema after the resource was created, with values leaving the file as it
+// is. Every other change requires resource replacement.
+func (r *sopsEncryptFileResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state sopsEncryptFileResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = state.ID
+	plan.Encrypted = state.Encrypted
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete removes the file.
+func (r *sopsEncryptFileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state sopsEncryptFileResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	filename := state.Filename.ValueString()
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		resp.Diagnostics.AddError(
+			"Error Deleting File",
+			fmt.Sprintf("Could not delete %s: %s", filename, err),
+		)
+	}
+}
+
+// parseFilePermission parses file permissions in octal notation, such as "0600".
+func parseFilePermission(permission string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(permission, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("file permission must be in octal notation between \"0000\" and \"0777\", got %q", permission)
+	}
+	return os.FileMode(mode), nil
+}
+
+// writeFileAtomic writes data to a temporary file next to filename and renames it over filename,
+// so readers never observe a partially written file.
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,149 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSopsEncryptFileResource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "secrets", "test.enc.yaml")
+	config := fmt.Sprintf(`
+					resource "sopsage_encrypted_file" "test" {
+					  filename = "%s"
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					}
+				`, filename, agePubkey)
+
+	checkFile := func(value string) error {
+		onDisk, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if string(onDisk) != value {
+			return fmt.Errorf("file content does not match the encrypted attribute")
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if info.Mode().Perm() != 0o600 {
+			return fmt.Errorf("unexpected file permission %s", info.Mode().Perm())
+		}
+		return nil
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		CheckDestroy: func(*terraform.State) error {
+			if _, err := os.Stat(filename); !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("file %s was not deleted", filename)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttrWith("sopsage_encrypted_file.test", "encrypted", checkFile),
+			},
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			{
+				PreConfig: func() {
+					encrypted, err := SopsEncryptDataFromAgeKeys("foo: baz\n", "yaml", []string{agePubkey}, nil)
+					assert.NoError(t, err)
+					assert.NoError(t, os.WriteFile(filename, []byte(encrypted), 0o600))
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_file.test", "encrypted", checkFile),
+			},
+			{
+				// An edit keeping the SOPS MAC, such as a reformat, is drift as well.
+				PreConfig: func() {
+					onDisk, err := os.ReadFile(filename)
+					assert.NoError(t, err)
+					assert.NoError(t, os.WriteFile(filename, append(onDisk, '\n'), 0o600))
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_file.test", "encrypted", checkFile),
+			},
+			{
+				PreConfig: func() {
+					assert.NoError(t, os.Chmod(filename, 0o644))
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_file.test", "encrypted", checkFile),
+			},
+			{
+				PreConfig: func() {
+					assert.NoError(t, os.Remove(filename))
+				},
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_file.test", plancheck.ResourceActionCreate),
+					},
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_file.test", "encrypted", checkFile),
+			},
+		},
+	})
+}
+
+func TestParseFilePermission(t *testing.T) {
+	testCases := []struct {
+		permission string
+		want       os.FileMode
+		wantErr    bool
+	}{
+		{permission: "0600", want: 0o600},
+		{permission: "644", want: 0o644},
+		{permission: "0777", want: 0o777},
+		{permission: "1777", wantErr: true},
+		{permission: "0800", wantErr: true},
+		{permission: "rw-------", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.permission, func(t *testing.T) {
+			got, err := parseFilePermission(tc.permission)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -1,24 +1,34 @@
 package provider
 
 import (
+	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"strings"
+	"time"
 
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
 var (
-	_ resource.Resource              = &sopsEncryptResource{}
-	_ resource.ResourceWithConfigure = &sopsEncryptResource{}
+	_ resource.Resource                     = &sopsEncryptResource{}
+	_ resource.ResourceWithConfigure        = &sopsEncryptResource{}
+	_ resource.ResourceWithModifyPlan       = &sopsEncryptResource{}
+	_ resource.ResourceWithValidateConfig   = &sopsEncryptResource{}
+	_ resource.ResourceWithConfigValidators = &sopsEncryptResource{}
+	_ resource.ResourceWithUpgradeState     = &sopsEncryptResource{}
 )
 
 // NewSopsEncryptResource is a helper function to simplify the provider implementation.
@@ -28,26 +38,34 @@ func NewSopsEncryptResource() resource.Resource {
 
 // sopsEncryptResource is the resource implementation.
 type sopsEncryptResource struct {
+	providerData *sopsAgeProviderData
 }
 
 // sopsEncryptResourceModel maps the resource schema data.
 type sopsEncryptResourceModel struct {
-	ID                      types.String `tfsdk:"id"`
-	Content                 types.String `tfsdk:"content"`
-	Format                  types.String `tfsdk:"format"`
-	AgePublicKeys           types.List   `tfsdk:"age_public_keys"`
-	UnencryptedSuffix       types.String `tfsdk:"unencrypted_suffix"`
-	EncryptedSuffix         types.String `tfsdk:"encrypted_suffix"`
-	UnencryptedRegex        types.String `tfsdk:"unencrypted_regex"`
-	EncryptedRegex          types.String `tfsdk:"encrypted_regex"`
-	UnencryptedCommentRegex types.String `tfsdk:"unencrypted_comment_regex"`
-	EncryptedCommentRegex   types.String `tfsdk:"encrypted_comment_regex"`
-	Encrypted               types.String `tfsdk:"encrypted"`
+	ID               types.String           `tfsdk:"id"`
+	Content          types.String           `tfsdk:"content"`
+	Format           types.String           `tfsdk:"format"`
+	AgePublicKeys    types.List             `tfsdk:"age_public_keys"`
+	PgpPublicKeys    types.List             `tfsdk:"pgp_public_keys"`
+	VaultTransitKeys []vaultTransitKeyModel `tfsdk:"vault_transit_keys"`
+	sopsEncryptionRulesModel
+	sopsStoreFormattingModel
+	RotateAfter     types.String `tfsdk:"rotate_after"`
+	RotationTrigger types.String `tfsdk:"rotation_trigger"`
+	RotatedAt       types.String `tfsdk:"rotated_at"`
+	// Paths previewing the encryption rules
+	EncryptedPaths        types.List   `tfsdk:"encrypted_paths"`
+	CleartextPaths        types.List   `tfsdk:"cleartext_paths"`
+	RequireEncryptedPaths types.List   `tfsdk:"require_encrypted_paths"`
+	Encrypted             types.String `tfsdk:"encrypted"`
 }
 
-// Configure adds the provider configured client to the resource.
-func (r *sopsEncryptResource) Configure(_ context.Context, req resource.ConfigureRequest, _ *resource.ConfigureResponse) {
-	// No configuration needed for this resource
+// Configure adds the provider data to the resource.
+func (r *sopsEncryptResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.providerData = providerData
 }
 
 // Metadata returns the resource type name.
@@ -57,88 +75,108 @@ func (r *sopsEncryptResource) Metadata(_ context.Context, req resource.MetadataR
 
 // Schema defines the schema for the resource.
 func (r *sopsEncryptResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
-	resp.Schema = schema.Schema{
-		Description: "Encrypts content using SOPS with age encryption.",
-		Attributes: map[string]schema.Attribute{
-			"id": schema.StringAttribute{
-				Description: "Identifier for the resource.",
-				Computed:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.UseStateForUnknown(),
-				},
-			},
-			"content": schema.StringAttribute{
-				Description: "The content to encrypt.",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
-			},
-			"format": schema.StringAttribute{
-				Description: "The format of the content (json, yaml, etc.).",
-				Required:    true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
+	attributes := map[string]schema.Attribute{
+		"id": schema.StringAttribute{
+			Description: "Identifier for the resource.",
+			Computed:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"age_public_keys": schema.ListAttribute{
-				Description: "List of age public keys to encrypt with.",
-				Required:    true,
-				ElementType: types.StringType,
-				PlanModifiers: []planmodifier.List{
-					listplanmodifier.RequiresReplace(),
-				},
+		},
+		"content": schema.StringAttribute{
+			Description: "The content to encrypt. Changes that keep the same values, such as whitespace, quoting or key order, " +
+				"are applied in place without encrypting the content again.",
+			Required: true,
+			PlanModifiers: []planmodifier.String{
+				contentRequiresReplace(),
 			},
-			"unencrypted_suffix": schema.StringAttribute{
-				Description:   "Unencrypted suffix, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
-				Optional:      true,
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Default:       stringdefault.StaticString(""),
+		},
+		"format": schema.StringAttribute{
+			Description: "The format of the content (json, yaml, etc.).",
+			Required:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
 			},
-			"encrypted_suffix": schema.StringAttribute{
-				Description:   "Encrypted suffix, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
-				Optional:      true,
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Default:       stringdefault.StaticString(""),
+		},
+		"age_public_keys": schema.ListAttribute{
+			Description: "List of age public keys to encrypt with.",
+			Required:    true,
+			ElementType: types.StringType,
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.RequiresReplace(),
 			},
-			"unencrypted_regex": schema.StringAttribute{
-				Description:   "Unencrypted regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
-				Optional:      true,
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Default:       stringdefault.StaticString(""),
+		},
+		"pgp_public_keys": schema.ListAttribute{
+			Description: "List of armored PGP public keys to encrypt with, alongside the age public keys. " +
+				"The keys are used as given, without a local GnuPG keyring.",
+			Optional:    true,
+			ElementType: types.StringType,
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.RequiresReplace(),
 			},
-			"encrypted_regex": schema.StringAttribute{
-				Description:   "Encrypted regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
-				Optional:      true,
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Default:       stringdefault.StaticString(""),
+		},
+		"vault_transit_keys": schema.ListNestedAttribute{
+			Description: "List of Vault transit keys to encrypt with, alongside the age public keys. " +
+				"They are reached with the Vault settings of the provider.",
+			Optional: true,
+			NestedObject: schema.NestedAttributeObject{
+				Attributes: vaultTransitKeyAttributes(),
 			},
-			"unencrypted_comment_regex": schema.StringAttribute{
-				Description:   "Unencrypted comment regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
-				Optional:      true,
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Default:       stringdefault.StaticString(""),
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.RequiresReplace(),
 			},
-			"encrypted_comment_regex": schema.StringAttribute{
-				Description:   "Encrypted comment regex",
-				Optional:      true,
-				Computed:      true,
-				PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
-				Default:       stringdefault.StaticString(""),
+		},
+		"rotate_after": schema.StringAttribute{
+			Description: "Duration after which the data key is rotated, such as \"8760h\". " +
+				"Once it has elapsed since rotated_at, the plan shows an in-place update that re-encrypts the content with a new data key.",
+			Optional: true,
+		},
+		"rotation_trigger": schema.StringAttribute{
+			Description: "Arbitrary value that rotates the data key in place whenever it changes.",
+			Optional:    true,
+		},
+		"rotated_at": schema.StringAttribute{
+			Description: "RFC3339 timestamp of the last data key generation.",
+			Computed:    true,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
-			"encrypted": schema.StringAttribute{
-				Description: "The encrypted content in SOPS format.",
-				Computed:    true,
-				Sensitive:   false,
+		},
+		"encrypted_paths": schema.ListAttribute{
+			Description: "Paths of the values the encryption rules encrypt, known at plan time. " +
+				"Paths use the syntax of sops --extract, such as [\"a\"][\"b\"], and list items share the path of their list.",
+			Computed:    true,
+			ElementType: types.StringType,
+		},
+		"cleartext_paths": schema.ListAttribute{
+			Description: "Paths of the values the encryption rules leave in cleartext, known at plan time.",
+			Computed:    true,
+			ElementType: types.StringType,
+		},
+		"require_encrypted_paths": schema.ListAttribute{
+			Description: "Paths that must be encrypted, in the syntax of encrypted_paths. " +
+				"The plan fails when one of them would be left in cleartext or is missing from the content. " +
+				"A path of a mapping requires all of its values to be encrypted.",
+			Optional:    true,
+			ElementType: types.StringType,
+		},
+		"encrypted": schema.StringAttribute{
+			Description: "The encrypted content in SOPS format.",
+			Computed:    true,
+			Sensitive:   false,
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.UseStateForUnknown(),
 			},
 		},
 	}
+	maps.Copy(attributes, sopsEncryptionRulesAttributes())
+	maps.Copy(attributes, sopsStoreFormattingAttributes())
 
+	resp.Schema = schema.Schema{
+		Description: "Encrypts content using SOPS with age encryption.",
+		Version:     1,
+		Attributes:  attributes,
+	}
 }
 
 // Create creates a new encrypted content.
@@ -151,48 +189,28 @@ func (r *sopsEncryptResource) Create(ctx context.Context, req resource.CreateReq
 		return
 	}
 
-	// Get the content and format
-	content := plan.Content.ValueString()
-	format := plan.Format.ValueString()
-
-	// Get the age public keys
-	var agePublicKeys []string
-	diags = plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)
+	// Encrypt the content
+	encrypted, diags := r.encrypt(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	encryptionConfig := &EncryptionConfig{
-		UnencryptedSuffix:       plan.UnencryptedSuffix.ValueString(),
-		EncryptedSuffix:         plan.EncryptedSuffix.ValueString(),
-		UnencryptedRegex:        plan.UnencryptedRegex.ValueString(),
-		EncryptedRegex:          plan.EncryptedRegex.ValueString(),
-		UnencryptedCommentRegex: plan.UnencryptedCommentRegex.ValueString(),
-		EncryptedCommentRegex:   plan.EncryptedCommentRegex.ValueString(),
-	}
-	// Encrypt the content
-	encrypted, err := SopsEncryptDataFromAgeKeys(content, format, agePublicKeys, encryptionConfig)
+	// Generate a random ID, an ID derived from the content would reveal it to brute force
+	id, err := uuid.GenerateUUID()
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Error Encrypting Content",
-			fmt.Sprintf("Could not encrypt content: %s", err),
+			"Error Generating ID",
+			fmt.Sprintf("Could not generate resource ID: %s", err),
 		)
 		return
 	}
 
-	// Generate a unique ID based on the content and keys
-	h := sha256.New()
-	h.Write([]byte(content))
-	for _, key := range agePublicKeys {
-		h.Write([]byte(key))
-	}
-	id := base64.StdEncoding.EncodeToString(h.Sum(nil))
-
 	// Set resource ID
 	plan.ID = types.StringValue(id)
 	// Set encrypted content
 	plan.Encrypted = types.StringValue(encrypted)
+	plan.RotatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
 
 	// Set state to computed values
 	diags = resp.State.Set(ctx, plan)
@@ -220,12 +238,240 @@ func (r *sopsEncryptResource) Read(ctx context.Context, req resource.ReadRequest
 	}
 }
 
-// Update updates the resource and sets the updated Terraform state on success.
+// Update rotates the data key or reformats the encrypted content when planned, and records content changes that keep
+// the same values. Every other change requires resource replacement.
 func (r *sopsEncryptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	resp.Diagnostics.AddError(
-		"Resource Does Not Support Update",
-		"The sops_encrypt resource does not support updates. All changes require resource replacement.",
-	)
+	var plan, state sopsEncryptResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	switch {
+	case plan.RotatedAt.IsUnknown():
+		encrypted, diags := r.encrypt(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.Encrypted = types.StringValue(encrypted)
+		plan.RotatedAt = types.StringValue(time.Now().UTC().Format(time.RFC3339))
+	case plan.Encrypted.IsUnknown():
+		stores := plan.sopsStoreFormattingModel.StoresConfig(r.providerData.storesConfig())
+		encrypted, err := SopsReformatData(state.Encrypted.ValueString(), plan.Format.ValueString(), stores)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reformatting Content",
+				fmt.Sprintf("Could not reformat the encrypted content: %s", err),
+			)
+			return
+		}
+		plan.Encrypted = types.StringValue(encrypted)
+		plan.RotatedAt = state.RotatedAt
+	default:
+		plan.Encrypted = state.Encrypted
+		plan.RotatedAt = state.RotatedAt
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// UpgradeState upgrades the state of earlier schema versions.
+func (r *sopsEncryptResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 used an unsalted SHA-256 of the content and the age public keys as ID.
+		0: {
+			StateUpgrader: func(ctx context.Context, req resource.UpgradeStateRequest, resp *resource.UpgradeStateResponse) {
+				var rawState map[string]any
+				decoder := json.NewDecoder(bytes.NewReader(req.RawState.JSON))
+				decoder.UseNumber()
+				if err := decoder.Decode(&rawState); err != nil {
+					resp.Diagnostics.AddError(
+						"Error Upgrading State",
+						fmt.Sprintf("Could not decode the prior state: %s", err),
+					)
+					return
+				}
+				id, err := uuid.GenerateUUID()
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Error Upgrading State",
+						fmt.Sprintf("Could not generate resource ID: %s", err),
+					)
+					return
+				}
+				rawState["id"] = id
+				upgraded, err := json.Marshal(rawState)
+				if err != nil {
+					resp.Diagnostics.AddError(
+						"Error Upgrading State",
+						fmt.Sprintf("Could not encode the upgraded state: %s", err),
+					)
+					return
+				}
+				resp.DynamicValue = &tfprotov6.DynamicValue{JSON: upgraded}
+			},
+		},
+	}
+}
+
+// ConfigValidators rejects conflicting encryption rules.
+func (r *sopsEncryptResource) ConfigValidators(_ context.Context) []resource.ConfigValidator {
+	return sopsEncryptionRulesConfigValidators()
+}
+
+// ValidateConfig validates the rotation settings.
+func (r *sopsEncryptResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config sopsEncryptResourceModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.RotateAfter.IsNull() || config.RotateAfter.IsUnknown() {
+		return
+	}
+	if _, err := parseRotateAfter(config.RotateAfter.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rotate_after"),
+			"Invalid Rotation Duration",
+			err.Error(),
+		)
+	}
+}
+
+// ModifyPlan previews the encrypted paths and plans a data key rotation when the rotation trigger changed or the
+// rotation duration elapsed, or a reformat when the output formatting of the resource or the provider changed.
+func (r *sopsEncryptResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destruction
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan sopsEncryptResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.planEncryptedPaths(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Nothing to rotate on creation
+	if !req.State.Raw.IsNull() {
+		var state sopsEncryptResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		rotate := !plan.RotationTrigger.Equal(state.RotationTrigger)
+		if !plan.RotateAfter.IsNull() && !plan.RotateAfter.IsUnknown() {
+			due, err := rotationDue(state.RotatedAt.ValueString(), plan.RotateAfter.ValueString(), time.Now())
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("rotate_after"),
+					"Invalid Rotation Duration",
+					err.Error(),
+				)
+				return
+			}
+			rotate = rotate || due
+		}
+		if rotate {
+			plan.Encrypted = types.StringUnknown()
+			plan.RotatedAt = types.StringUnknown()
+		} else if r.reformatPlanned(plan, state) {
+			// Reformat the encrypted content, keeping its data key
+			plan.Encrypted = types.StringUnknown()
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// reformatPlanned reports whether the encrypted content of state differs from its output with the formatting of the
+// resource and the provider, so that changing either reformats it.
+func (r *sopsEncryptResource) reformatPlanned(plan sopsEncryptResourceModel, state sopsEncryptResourceModel) bool {
+	formatting := plan.sopsStoreFormattingModel
+	if formatting.YAMLIndent.IsUnknown() || formatting.JSONIndent.IsUnknown() || formatting.JSONBinaryIndent.IsUnknown() {
+		return true
+	}
+	if !formatting.Equal(state.sopsStoreFormattingModel) {
+		return true
+	}
+	// The provider formatting is unknown until the provider is configured.
+	if r.providerData == nil || plan.Format.IsUnknown() {
+		return false
+	}
+	stores := formatting.StoresConfig(r.providerData.storesConfig())
+	reformatted, err := SopsReformatData(state.Encrypted.ValueString(), plan.Format.ValueString(), stores)
+	// Content that cannot be parsed is not reformatted.
+	return err == nil && reformatted != state.Encrypted.ValueString()
+}
+
+// planEncryptedPaths sets the encrypted and cleartext paths of the plan, and checks the required encrypted paths. The
+// paths are left unknown until the content, the format and the encryption rules are known.
+func (r *sopsEncryptResource) planEncryptedPaths(ctx context.Context, plan *sopsEncryptResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	rules := plan.sopsEncryptionRulesModel
+	for _, value := range []types.String{
+		plan.Format, rules.UnencryptedSuffix, rules.EncryptedSuffix, rules.UnencryptedRegex, rules.EncryptedRegex,
+		rules.UnencryptedCommentRegex, rules.EncryptedCommentRegex,
+	} {
+		if value.IsUnknown() {
+			plan.EncryptedPaths = types.ListUnknown(types.StringType)
+			plan.CleartextPaths = types.ListUnknown(types.StringType)
+			return diags
+		}
+	}
+	if plan.Content.IsUnknown() {
+		plan.EncryptedPaths = types.ListUnknown(types.StringType)
+		plan.CleartextPaths = types.ListUnknown(types.StringType)
+		return diags
+	}
+
+	encrypted, cleartext, err := SopsEncryptedPaths(plan.Content.ValueString(), plan.Format.ValueString(), plan.EncryptionConfig())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("content"),
+			"Error Loading Content",
+			fmt.Sprintf("Could not load the content to preview its encrypted paths: %s", err),
+		)
+		return diags
+	}
+	var d diag.Diagnostics
+	plan.EncryptedPaths, d = types.ListValueFrom(ctx, types.StringType, encrypted)
+	diags.Append(d...)
+	plan.CleartextPaths, d = types.ListValueFrom(ctx, types.StringType, cleartext)
+	diags.Append(d...)
+	if diags.HasError() || plan.RequireEncryptedPaths.IsNull() || plan.RequireEncryptedPaths.IsUnknown() {
+		return diags
+	}
+
+	var required []string
+	diags.Append(plan.RequireEncryptedPaths.ElementsAs(ctx, &required, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	if uncovered := UncoveredPaths(required, encrypted, cleartext); len(uncovered) > 0 {
+		diags.AddAttributeError(
+			path.Root("require_encrypted_paths"),
+			"Required Paths Not Encrypted",
+			fmt.Sprintf("The encryption rules would leave these required paths in cleartext, or they are missing from the content: %s. "+
+				"The encrypted paths are: %s.", strings.Join(uncovered, ", "), strings.Join(encrypted, ", ")),
+		)
+	}
+	return diags
 }
 
 // Delete deletes the resource and removes the Terraform state on success.
@@ -233,3 +479,69 @@ func (r *sopsEncryptResource) Delete(ctx context.Context, req resource.DeleteReq
 	// Encrypted content doesn't have any external resources to clean up
 	// The state will be removed by Terraform automatically
 }
+
+// encrypt encrypts the planned content with a new data key.
+func (r *sopsEncryptResource) encrypt(ctx context.Context, plan sopsEncryptResourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	// Get the content and format
+	content := plan.Content.ValueString()
+	format := plan.Format.ValueString()
+
+	// Get the age public keys
+	var agePublicKeys []string
+	diags.Append(plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	encryptionConfig := plan.EncryptionConfig()
+	diags.Append(plan.PgpPublicKeys.ElementsAs(ctx, &encryptionConfig.PgpPublicKeys, false)...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	encryptionConfig.VaultTransitKeys = vaultTransitKeys(plan.VaultTransitKeys)
+	encryptionConfig.KeyServices = r.providerData.keyServiceConfig()
+	encryptionConfig.Stores = plan.sopsStoreFormattingModel.StoresConfig(r.providerData.storesConfig())
+
+	// Encrypt the content
+	encrypted, err := SopsEncryptDataFromAgeKeys(content, format, agePublicKeys, encryptionConfig)
+	if err != nil {
+		diags.AddError(
+			"Error Encrypting Content",
+			fmt.Sprintf("Could not encrypt content: %s", err),
+		)
+		return "", diags
+	}
+	return encrypted, diags
+}
+
+// parseRotateAfter parses a positive rotation duration.
+func parseRotateAfter(rotateAfter string) (time.Duration, error) {
+	d, err := time.ParseDuration(rotateAfter)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("rotation duration must be positive, got %q", rotateAfter)
+	}
+	return d, nil
+}
+
+// rotationDue reports whether a data key generated at rotatedAt must be rotated at now.
+// A missing timestamp, as left by resources created before rotation support, is always due.
+func rotationDue(rotatedAt string, rotateAfter string, now time.Time) (bool, error) {
+	d, err := parseRotateAfter(rotateAfter)
+	if err != nil {
+		return false, err
+	}
+	if rotatedAt == "" {
+		return true, nil
+	}
+	t, err := time.Parse(time.RFC3339, rotatedAt)
+	if err != nil {
+		return false, err
+	}
+	return !now.Before(t.Add(d)), nil
+}
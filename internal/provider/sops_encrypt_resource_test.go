@@ -1,11 +1,21 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"testing"
+	"time"
 
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSopsEncryptResourceResource(t *testing.T) {
@@ -43,3 +53,262 @@ func TestSopsEncryptResourceResource(t *testing.T) {
 		},
 	})
 }
+
+func TestSopsEncryptResourceRotationTrigger(t *testing.T) {
+	config := func(trigger string) string {
+		return fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					  rotate_after = "8760h"
+					  rotation_trigger = "%s"
+					}
+				`, agePubkey, trigger)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("2025"),
+			},
+			{
+				Config: config("2025"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			{
+				Config: config("2026"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestRotationDue(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name        string
+		rotatedAt   string
+		rotateAfter string
+		want        bool
+		wantErr     bool
+	}{
+		{
+			name:        "not yet due",
+			rotatedAt:   "2025-06-01T00:00:00Z",
+			rotateAfter: "8760h",
+			want:        false,
+		},
+		{
+			name:        "due",
+			rotatedAt:   "2025-01-01T00:00:00Z",
+			rotateAfter: "8760h",
+			want:        true,
+		},
+		{
+			name:        "missing timestamp",
+			rotatedAt:   "",
+			rotateAfter: "8760h",
+			want:        true,
+		},
+		{
+			name:        "invalid duration",
+			rotatedAt:   "2025-01-01T00:00:00Z",
+			rotateAfter: "1y",
+			wantErr:     true,
+		},
+		{
+			name:        "negative duration",
+			rotatedAt:   "2025-01-01T00:00:00Z",
+			rotateAfter: "-1h",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rotationDue(tc.rotatedAt, tc.rotateAfter, now)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSopsEncryptResourceMultiDocument(t *testing.T) {
+	config := fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = join("---\n", [yamlencode({foo = "bar"}), yamlencode({baz = "qux"})])
+					  age_public_keys = ["%s"]
+					}
+				`, agePubkey)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					decrypted, err := SopsDecryptDataFromAgeKey(value, "yaml", agePrivkey)
+					if err != nil {
+						return err
+					}
+					if decrypted != "foo: bar\n---\nbaz: qux\n" {
+						return fmt.Errorf("unexpected decrypted content:\n%s", decrypted)
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
+
+func TestSopsEncryptResourceUpgradeState(t *testing.T) {
+	priorState := map[string]any{
+		"id":              "qUqP5cyxm6YcTAhz05Hph5gvu9M=",
+		"content":         "foo: bar\n",
+		"format":          "yaml",
+		"age_public_keys": []any{agePubkey},
+		"encrypted":       "sops: {}\n",
+	}
+	rawState, err := json.Marshal(priorState)
+	require.NoError(t, err)
+
+	upgrader := NewSopsEncryptResource().(fwresource.ResourceWithUpgradeState).UpgradeState(context.Background())[0]
+	resp := &fwresource.UpgradeStateResponse{}
+	upgrader.StateUpgrader(context.Background(), fwresource.UpgradeStateRequest{
+		RawState: &tfprotov6.RawState{JSON: rawState},
+	}, resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+
+	var upgraded map[string]any
+	require.NoError(t, json.Unmarshal(resp.DynamicValue.JSON, &upgraded))
+	assert.Regexp(t, "^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$", upgraded["id"])
+	delete(upgraded, "id")
+	delete(priorState, "id")
+	assert.Equal(t, priorState, upgraded)
+}
+
+func TestSopsEncryptResourceEncryptedPaths(t *testing.T) {
+	config := func(encryptedRegex string, required string) string {
+		return fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = yamlencode({password = "secret", user = "admin"})
+					  encrypted_regex = %q
+					  require_encrypted_paths = [%q]
+					  age_public_keys = ["%s"]
+					}
+				`, encryptedRegex, required, agePubkey)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("^user$", `["password"]`),
+				ExpectError: regexp.MustCompile(`Required Paths Not Encrypted`),
+			},
+			{
+				Config: config("^pass", `["password"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted_paths"),
+							knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact(`["password"]`)})),
+						plancheck.ExpectKnownValue("sopsage_encrypted_data.test", tfjsonpath.New("cleartext_paths"),
+							knownvalue.ListExact([]knownvalue.Check{knownvalue.StringExact(`["user"]`)})),
+					},
+				},
+			},
+			{
+				Config: config("^pass", `["password"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestSopsEncryptResourceEncryptionRulesValidation(t *testing.T) {
+	config := func(rules string) string {
+		return fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					  %s
+					}
+				`, agePubkey, rules)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("encrypted_regex = \"^foo\"\nunencrypted_suffix = \"_plain\""),
+				ExpectError: regexp.MustCompile(`Invalid Attribute Combination`),
+			},
+			{
+				Config:      config("encrypted_regex = \"^(foo\""),
+				ExpectError: regexp.MustCompile(`Invalid Regular Expression`),
+			},
+			{
+				Config:      config("unencrypted_comment_regex = \"[\""),
+				ExpectError: regexp.MustCompile(`Invalid Regular Expression`),
+			},
+		},
+	})
+}
+
+func TestSopsEncryptResourceMACOnlyEncrypted(t *testing.T) {
+	config := fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = yamlencode({password = "secret", replicas = 1})
+					  encrypted_regex = "^password$"
+					  mac_only_encrypted = true
+					  age_public_keys = ["%s"]
+					}
+				`, agePubkey)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					metadata, err := SopsLoadMetadata(value, "yaml")
+					if err != nil {
+						return err
+					}
+					if !metadata.MACOnlyEncrypted {
+						return fmt.Errorf("mac_only_encrypted is not set in the SOPS metadata")
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
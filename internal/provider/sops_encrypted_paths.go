@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	"github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/config"
+)
+
+// SopsEncryptedPaths returns the paths of the values the encryption rules would encrypt, and of the values they would
+// leave in cleartext. Paths use the syntax of sops --extract, such as ["a"]["b"], and list items share the path of
+// their list. Comments are not reported.
+func SopsEncryptedPaths(data string, format string, encryptionConfig *EncryptionConfig) (encrypted []string, cleartext []string, err error) {
+	if encryptionConfig == nil {
+		encryptionConfig = DefaultEncryptionConfig()
+	}
+	store := common.StoreForFormat(formats.FormatFromString(format), config.NewStoresConfig())
+	branches, err := store.LoadPlainFile([]byte(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tree := sops.Tree{
+		Branches: dropEmptyDocuments(branches),
+		Metadata: encryptionConfig.metadata(),
+	}
+	// Walk the tree with the rules of sops itself, recording the values it asks to encrypt instead of encrypting them.
+	recorder := &pathRecorder{encrypted: map[string]bool{}}
+	if _, err := tree.Encrypt(nil, recorder); err != nil {
+		return nil, nil, err
+	}
+
+	encrypted, cleartext = []string{}, []string{}
+	for _, p := range sopsLeafPaths(tree.Branches) {
+		// sops authenticates each value with its path joined and terminated with colons.
+		if recorder.encrypted[strings.Join(p, ":")+":"] {
+			encrypted = append(encrypted, sopsPath(p))
+		} else {
+			cleartext = append(cleartext, sopsPath(p))
+		}
+	}
+	return slices.Compact(encrypted), slices.Compact(cleartext), nil
+}
+
+// pathRecorder is a sops.Cipher recording the additional data, that is the path, of the values it is asked to encrypt.
+type pathRecorder struct {
+	encrypted map[string]bool
+}
+
+// Encrypt records the path of a value, comments are ignored.
+func (r *pathRecorder) Encrypt(plaintext interface{}, _ []byte, additionalData string) (string, error) {
+	if _, ok := plaintext.(sops.Comment); !ok {
+		r.encrypted[additionalData] = true
+	}
+	return "", nil
+}
+
+// Decrypt is not supported.
+func (r *pathRecorder) Decrypt(string, []byte, string) (interface{}, error) {
+	return nil, fmt.Errorf("the path recorder cannot decrypt")
+}
+
+// sopsLeafPaths returns the paths of the values of the documents, sorted.
+func sopsLeafPaths(branches sops.TreeBranches) [][]string {
+	var paths [][]string
+	var walk func(value interface{}, path []string)
+	walk = func(value interface{}, path []string) {
+		switch value := value.(type) {
+		case sops.TreeBranch:
+			for _, item := range value {
+				if key, ok := item.Key.(string); ok {
+					walk(item.Value, append(slices.Clone(path), key))
+				}
+			}
+		case []interface{}:
+			for _, v := range value {
+				walk(v, path)
+			}
+		case sops.Comment, nil:
+		default:
+			paths = append(paths, path)
+		}
+	}
+	for _, branch := range branches {
+		walk(branch, nil)
+	}
+	slices.SortFunc(paths, slices.Compare)
+	return paths
+}
+
+// sopsPath formats a path with the syntax of sops --extract.
+func sopsPath(path []string) string {
+	var b strings.Builder
+	for _, key := range path {
+		fmt.Fprintf(&b, "[%q]", key)
+	}
+	return b.String()
+}
+
+// UncoveredPaths returns the required paths that do not match any encrypted path, or match a cleartext path. A required
+// path matches the paths it is equal to or a prefix of, so that requiring a mapping requires all of its values.
+func UncoveredPaths(required []string, encrypted []string, cleartext []string) []string {
+	matches := func(required string, p string) bool {
+		return p == required || strings.HasPrefix(p, required+"[")
+	}
+	var uncovered []string
+	for _, r := range required {
+		covered := slices.ContainsFunc(encrypted, func(p string) bool { return matches(r, p) })
+		exposed := slices.ContainsFunc(cleartext, func(p string) bool { return matches(r, p) })
+		if !covered || exposed {
+			uncovered = append(uncovered, r)
+		}
+	}
+	return uncovered
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsEncryptedPaths(t *testing.T) {
+	content := "db:\n  user: admin\n  password: secret\nhosts:\n  - a\n  - b\nname_unencrypted: x\n"
+	testCases := []struct {
+		name      string
+		config    *EncryptionConfig
+		encrypted []string
+		cleartext []string
+	}{
+		{
+			name:      "default",
+			config:    nil,
+			encrypted: []string{`["db"]["password"]`, `["db"]["user"]`, `["hosts"]`},
+			cleartext: []string{`["name_unencrypted"]`},
+		},
+		{
+			name:      "unencrypted suffix",
+			config:    &EncryptionConfig{UnencryptedSuffix: "user"},
+			encrypted: []string{`["db"]["password"]`, `["hosts"]`, `["name_unencrypted"]`},
+			cleartext: []string{`["db"]["user"]`},
+		},
+		{
+			name:      "encrypted regex",
+			config:    &EncryptionConfig{EncryptedRegex: "^pass"},
+			encrypted: []string{`["db"]["password"]`},
+			cleartext: []string{`["db"]["user"]`, `["hosts"]`, `["name_unencrypted"]`},
+		},
+		{
+			name:      "unencrypted regex on a mapping",
+			config:    &EncryptionConfig{UnencryptedRegex: "^db$"},
+			encrypted: []string{`["hosts"]`, `["name_unencrypted"]`},
+			cleartext: []string{`["db"]["password"]`, `["db"]["user"]`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			encrypted, cleartext, err := SopsEncryptedPaths(content, "yaml", tc.config)
+			require.NoError(t, err)
+			assert.Equal(t, tc.encrypted, encrypted)
+			assert.Equal(t, tc.cleartext, cleartext)
+		})
+	}
+
+	encrypted, cleartext, err := SopsEncryptedPaths("A=1\n", "dotenv", &EncryptionConfig{EncryptedRegex: "^B"})
+	require.NoError(t, err)
+	assert.Empty(t, encrypted)
+	assert.Equal(t, []string{`["A"]`}, cleartext)
+
+	_, _, err = SopsEncryptedPaths("not json", "json", nil)
+	assert.Error(t, err)
+}
+
+func TestUncoveredPaths(t *testing.T) {
+	encrypted := []string{`["db"]["password"]`, `["hosts"]`}
+	cleartext := []string{`["db"]["user"]`, `["dbname"]`}
+
+	assert.Empty(t, UncoveredPaths([]string{`["db"]["password"]`, `["hosts"]`}, encrypted, cleartext))
+	assert.Equal(t, []string{`["db"]`}, UncoveredPaths([]string{`["db"]`}, encrypted, cleartext))
+	assert.Equal(t, []string{`["missing"]`}, UncoveredPaths([]string{`["missing"]`}, encrypted, cleartext))
+	assert.Equal(t, []string{`["db"]["user"]`}, UncoveredPaths([]string{`["db"]["user"]`}, encrypted, cleartext))
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// sopsEncryptionRuleAttributes are the names of the encryption rules, SOPS allows only one of them per document.
+var sopsEncryptionRuleAttributes = []string{
+	"unencrypted_suffix",
+	"encrypted_suffix",
+	"unencrypted_regex",
+	"encrypted_regex",
+	"unencrypted_comment_regex",
+	"encrypted_comment_regex",
+}
+
+// sopsEncryptionRulesModel maps the encryption rules shared by the resources producing SOPS documents.
+type sopsEncryptionRulesModel struct {
+	UnencryptedSuffix       types.String `tfsdk:"unencrypted_suffix"`
+	EncryptedSuffix         types.String `tfsdk:"encrypted_suffix"`
+	UnencryptedRegex        types.String `tfsdk:"unencrypted_regex"`
+	EncryptedRegex          types.String `tfsdk:"encrypted_regex"`
+	UnencryptedCommentRegex types.String `tfsdk:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   types.String `tfsdk:"encrypted_comment_regex"`
+	MACOnlyEncrypted        types.Bool   `tfsdk:"mac_only_encrypted"`
+}
+
+// EncryptionConfig returns the encryption configuration matching the rules.
+func (m sopsEncryptionRulesModel) EncryptionConfig() *EncryptionConfig {
+	return &EncryptionConfig{
+		UnencryptedSuffix:       m.UnencryptedSuffix.ValueString(),
+		EncryptedSuffix:         m.EncryptedSuffix.ValueString(),
+		UnencryptedRegex:        m.UnencryptedRegex.ValueString(),
+		EncryptedRegex:          m.EncryptedRegex.ValueString(),
+		UnencryptedCommentRegex: m.UnencryptedCommentRegex.ValueString(),
+		EncryptedCommentRegex:   m.EncryptedCommentRegex.ValueString(),
+		MACOnlyEncrypted:        m.MACOnlyEncrypted.ValueBool(),
+	}
+}
+
+// sopsEncryptionRulesAttributes defines the schema of the encryption rules.
+func sopsEncryptionRulesAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"unencrypted_suffix": schema.StringAttribute{
+			Description:   "Unencrypted suffix, defaults to \"\". When no rule is set, SOPS leaves the keys ending with \"_unencrypted\" in cleartext. Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Default:       stringdefault.StaticString(""),
+		},
+		"encrypted_suffix": schema.StringAttribute{
+			Description:   "Encrypted suffix, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Default:       stringdefault.StaticString(""),
+		},
+		"unencrypted_regex": schema.StringAttribute{
+			Description:   "Unencrypted regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{validRegex()},
+			Default:       stringdefault.StaticString(""),
+		},
+		"encrypted_regex": schema.StringAttribute{
+			Description:   "Encrypted regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{validRegex()},
+			Default:       stringdefault.StaticString(""),
+		},
+		"unencrypted_comment_regex": schema.StringAttribute{
+			Description:   "Unencrypted comment regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{validRegex()},
+			Default:       stringdefault.StaticString(""),
+		},
+		"encrypted_comment_regex": schema.StringAttribute{
+			Description:   "Encrypted comment regex, defaults to \"\". Cannot use more than one of encrypted_suffix, unencrypted_suffix, encrypted_regex, unencrypted_regex, encrypted_comment_regex, or unencrypted_comment_regex in the same file.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.String{stringplanmodifier.RequiresReplace()},
+			Validators:    []validator.String{validRegex()},
+			Default:       stringdefault.StaticString(""),
+		},
+		"mac_only_encrypted": schema.BoolAttribute{
+			Description: "Compute the MAC over the encrypted values only, defaults to false. " +
+				"Cleartext values can then be edited by other tooling without breaking the integrity check of the document.",
+			Optional:      true,
+			Computed:      true,
+			PlanModifiers: []planmodifier.Bool{macOnlyEncryptedRequiresReplace()},
+			Default:       booldefault.StaticBool(false),
+		},
+	}
+}
+
+// sopsEncryptionRulesConfigValidators rejects configurations setting more than one encryption rule.
+func sopsEncryptionRulesConfigValidators() []resource.ConfigValidator {
+	expressions := make([]path.Expression, len(sopsEncryptionRuleAttributes))
+	for i, name := range sopsEncryptionRuleAttributes {
+		expressions[i] = path.MatchRoot(name)
+	}
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(expressions...),
+	}
+}
+
+// validRegex returns a validator checking that a string is a regular expression SOPS can compile.
+func validRegex() validator.String {
+	return validRegexValidator{}
+}
+
+// validRegexValidator validates that a string compiles as a Go regular expression, the syntax used by SOPS.
+type validRegexValidator struct{}
+
+// Description describes the validation.
+func (v validRegexValidator) Description(_ context.Context) string {
+	return "value must be a valid regular expression"
+}
+
+// MarkdownDescription describes the validation in Markdown.
+func (v validRegexValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+// ValidateString compiles the value.
+func (v validRegexValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := regexp.Compile(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Regular Expression",
+			fmt.Sprintf("Could not compile %q: %s", req.ConfigValue.ValueString(), err),
+		)
+	}
+}
+
+// macOnlyEncryptedRequiresReplace requires replacement when mac_only_encrypted changes. Resources created before the
+// attribute existed have it null in their state, and are not replaced for it taking its default.
+func macOnlyEncryptedRequiresReplace() planmodifier.Bool {
+	return boolplanmodifier.RequiresReplaceIf(
+		func(_ context.Context, req planmodifier.BoolRequest, resp *boolplanmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace = !req.StateValue.IsNull() || req.PlanValue.ValueBool()
+		},
+		"Changes to mac_only_encrypted require replacement.",
+		"Changes to mac_only_encrypted require replacement.",
+	)
+}
@@ -0,0 +1,441 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+
+	"github.com/getsops/sops/v3"
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource                   = &sopsGeneratedSecretResource{}
+	_ resource.ResourceWithConfigure      = &sopsGeneratedSecretResource{}
+	_ resource.ResourceWithModifyPlan     = &sopsGeneratedSecretResource{}
+	_ resource.ResourceWithValidateConfig = &sopsGeneratedSecretResource{}
+)
+
+// defaultGeneratedSecretCharset is the charset of generated passwords when none is set.
+const defaultGeneratedSecretCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// generatedSecretEncryptedRegex encrypts every generated secret, whatever its name.
+const generatedSecretEncryptedRegex = ".*"
+
+// NewSopsGeneratedSecretResource is a helper function to simplify the provider implementation.
+func NewSopsGeneratedSecretResource() resource.Resource {
+	return &sopsGeneratedSecretResource{}
+}
+
+// sopsGeneratedSecretResource is the resource implementation.
+type sopsGeneratedSecretResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// sopsGeneratedSecretResourceModel maps the resource schema data.
+type sopsGeneratedSecretResourceModel struct {
+	ID            types.String                    `tfsdk:"id"`
+	Format        types.String                    `tfsdk:"format"`
+	AgePublicKeys types.List                      `tfsdk:"age_public_keys"`
+	Secrets       map[string]generatedSecretModel `tfsdk:"secrets"`
+	Encrypted     types.String                    `tfsdk:"encrypted"`
+}
+
+// generatedSecretModel maps the generation settings of a secret.
+type generatedSecretModel struct {
+	Type            types.String `tfsdk:"type"`
+	Length          types.Int64  `tfsdk:"length"`
+	Charset         types.String `tfsdk:"charset"`
+	RotationTrigger types.String `tfsdk:"rotation_trigger"`
+}
+
+// Configure adds the provider data to the resource.
+func (r *sopsGeneratedSecretResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.providerData = providerData
+}
+
+// Metadata returns the resource type name.
+func (r *sopsGeneratedSecretResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_generated_secret"
+}
+
+// Schema defines the schema for the resource.
+func (r *sopsGeneratedSecretResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Generates random secrets and encrypts them into a SOPS document, keeping only the ciphertext in the state. " +
+			"Regenerating some of the secrets keeps the others, which requires the provider to decrypt the document with " +
+			"its age key files, environment identities or key services.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"format": schema.StringAttribute{
+				Description: "The format of the document (yaml, json or dotenv), defaults to yaml.",
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("yaml"),
+				Validators: []validator.String{
+					stringvalidator.OneOf("yaml", "json", "dotenv"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"age_public_keys": schema.ListAttribute{
+				Description: "List of age public keys to encrypt with.",
+				Required:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"secrets": schema.MapNestedAttribute{
+				Description: "Secrets to generate, by key of the document. " +
+					"A secret is regenerated when its settings or its rotation trigger change.",
+				Required: true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Description: "Kind of secret: \"password\" draws length characters from charset, " +
+								"\"hex\" and \"base64\" encode length random bytes. Defaults to \"password\".",
+							Optional: true,
+							Computed: true,
+							Default:  stringdefault.StaticString("password"),
+							Validators: []validator.String{
+								stringvalidator.OneOf("password", "hex", "base64"),
+							},
+						},
+						"length": schema.Int64Attribute{
+							Description: "Number of characters of a password, or of random bytes, defaults to 32.",
+							Optional:    true,
+							Computed:    true,
+							Default:     int64default.StaticInt64(32),
+							Validators: []validator.Int64{
+								int64validator.Between(1, 4096),
+							},
+						},
+						"charset": schema.StringAttribute{
+							Description: "Characters passwords are drawn from, defaults to ASCII letters and digits.",
+							Optional:    true,
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"rotation_trigger": schema.StringAttribute{
+							Description: "Arbitrary value that regenerates the secret whenever it changes.",
+							Optional:    true,
+						},
+					},
+				},
+			},
+			"encrypted": schema.StringAttribute{
+				Description: "The encrypted secrets in SOPS format.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+// ValidateConfig checks that charset is only set for passwords.
+func (r *sopsGeneratedSecretResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config sopsGeneratedSecretResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for key, secret := range config.Secrets {
+		if secret.Charset.IsNull() || secret.Type.IsNull() || secret.Type.IsUnknown() || secret.Type.ValueString() == "password" {
+			continue
+		}
+		resp.Diagnostics.AddAttributeError(
+			path.Root("secrets").AtMapKey(key).AtName("charset"),
+			"Invalid Secret Settings",
+			fmt.Sprintf("charset can only be set for passwords, not for %s secrets.", secret.Type.ValueString()),
+		)
+	}
+}
+
+// ModifyPlan plans new encrypted content when secrets are added, removed or regenerated.
+func (r *sopsGeneratedSecretResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to regenerate on creation or destruction
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state sopsGeneratedSecretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if maps.EqualFunc(plan.Secrets, state.Secrets, generatedSecretModel.Equal) {
+		return
+	}
+	plan.Encrypted = types.StringUnknown()
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// Create generates the secrets and encrypts them.
+func (r *sopsGeneratedSecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan sopsGeneratedSecretResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Generate and encrypt the secrets
+	encrypted, diags := r.generate(ctx, plan, nil)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Generating ID",
+			fmt.Sprintf("Could not generate resource ID: %s", err),
+		)
+		return
+	}
+
+	// Set resource ID
+	plan.ID = types.StringValue(id)
+	// Set encrypted content
+	plan.Encrypted = types.StringValue(encrypted)
+
+	// Set state to computed values
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *sopsGeneratedSecretResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state sopsGeneratedSecretResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update regenerates the secrets whose settings changed, keeping the others.
+func (r *sopsGeneratedSecretResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state sopsGeneratedSecretResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.Encrypted.IsUnknown() {
+		plan.Encrypted = state.Encrypted
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+
+	// Decrypt the secrets to keep, only when there are some
+	kept := map[string]string{}
+	for key, secret := range plan.Secrets {
+		if previous, ok := state.Secrets[key]; ok && secret.Equal(previous) {
+			kept[key] = ""
+		}
+	}
+	if len(kept) > 0 {
+		values, err := r.decrypt(state.Encrypted.ValueString(), state.Format.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Decrypting Secrets",
+				fmt.Sprintf("Could not decrypt the secrets to keep: %s. "+
+					"Configure an identity able to decrypt the document on the provider, or replace the resource to regenerate every secret.", err),
+			)
+			return
+		}
+		for key := range kept {
+			value, ok := values[key]
+			if !ok {
+				resp.Diagnostics.AddError(
+					"Error Decrypting Secrets",
+					fmt.Sprintf("The encrypted document has no secret %q.", key),
+				)
+				return
+			}
+			kept[key] = value
+		}
+	}
+
+	encrypted, diags := r.generate(ctx, plan, kept)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Encrypted = types.StringValue(encrypted)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *sopsGeneratedSecretResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Encrypted content doesn't have any external resources to clean up
+	// The state will be removed by Terraform automatically
+}
+
+// generate generates the planned secrets missing from kept and encrypts all of them, in key order.
+func (r *sopsGeneratedSecretResource) generate(ctx context.Context, plan sopsGeneratedSecretResourceModel, kept map[string]string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var agePublicKeys []string
+	diags.Append(plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)...)
+	if diags.HasError() {
+		return "", diags
+	}
+
+	var branch sops.TreeBranch
+	for _, key := range slices.Sorted(maps.Keys(plan.Secrets)) {
+		value, ok := kept[key]
+		if !ok {
+			var err error
+			value, err = generateSecret(plan.Secrets[key])
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("secrets").AtMapKey(key),
+					"Error Generating Secret",
+					fmt.Sprintf("Could not generate secret %q: %s", key, err),
+				)
+				return "", diags
+			}
+		}
+		branch = append(branch, sops.TreeItem{Key: key, Value: value})
+	}
+
+	format := plan.Format.ValueString()
+	content, err := sopsStore(format, nil).EmitPlainFile(sops.TreeBranches{branch})
+	if err != nil {
+		diags.AddError(
+			"Error Encrypting Secrets",
+			fmt.Sprintf("Could not serialize secrets: %s", err),
+		)
+		return "", diags
+	}
+	encrypted, err := SopsEncryptDataFromAgeKeys(string(content), format, agePublicKeys, &EncryptionConfig{
+		EncryptedRegex: generatedSecretEncryptedRegex,
+		KeyServices:    r.providerData.keyServiceConfig(),
+		Stores:         r.providerData.storesConfig(),
+	})
+	if err != nil {
+		diags.AddError(
+			"Error Encrypting Secrets",
+			fmt.Sprintf("Could not encrypt secrets: %s", err),
+		)
+		return "", diags
+	}
+	return encrypted, diags
+}
+
+// decrypt returns the secrets of an encrypted document, decrypted with the identities of the provider.
+func (r *sopsGeneratedSecretResource) decrypt(encrypted string, format string) (map[string]string, error) {
+	plaintext, err := SopsDecryptData(encrypted, format, &DecryptionConfig{
+		AgePrivateKey: r.providerData.agePrivateKey(nil),
+		KeyServices:   r.providerData.keyServiceConfig(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	branches, err := sopsStore(format, nil).LoadPlainFile([]byte(plaintext))
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	for _, branch := range branches {
+		for _, item := range branch {
+			key, keyOk := item.Key.(string)
+			value, valueOk := item.Value.(string)
+			if keyOk && valueOk {
+				values[key] = value
+			}
+		}
+	}
+	return values, nil
+}
+
+// Equal reports whether both secrets have the same settings.
+func (m generatedSecretModel) Equal(o generatedSecretModel) bool {
+	return m.Type.Equal(o.Type) && m.Length.Equal(o.Length) && m.Charset.Equal(o.Charset) && m.RotationTrigger.Equal(o.RotationTrigger)
+}
+
+// generateSecret draws a random secret with the settings of secret.
+func generateSecret(secret generatedSecretModel) (string, error) {
+	length := int(secret.Length.ValueInt64())
+	switch secret.Type.ValueString() {
+	case "hex", "base64":
+		b := make([]byte, length)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		if secret.Type.ValueString() == "hex" {
+			return hex.EncodeToString(b), nil
+		}
+		return base64.StdEncoding.EncodeToString(b), nil
+	default:
+		charset := []rune(defaultGeneratedSecretCharset)
+		if !secret.Charset.IsNull() {
+			charset = []rune(secret.Charset.ValueString())
+		}
+		password := make([]rune, length)
+		for i := range password {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+			if err != nil {
+				return "", err
+			}
+			password[i] = charset[n.Int64()]
+		}
+		return string(password), nil
+	}
+}
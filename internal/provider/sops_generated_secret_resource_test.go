@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsGeneratedSecretResource(t *testing.T) {
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	keyFile := writeAgeKeyFile(t, t.TempDir(), agePrivkey)
+
+	config := func(trigger string) string {
+		return fmt.Sprintf(`
+					provider "sopsage" {
+					  environment_identities = "ignore"
+					  age_key_file           = "%s"
+					}
+
+					resource "sopsage_generated_secret" "test" {
+					  age_public_keys = ["%s"]
+					  secrets = {
+						password = {
+						  length  = 12
+						  charset = "ab"
+						}
+						token = {
+						  type             = "hex"
+						  length           = 16
+						  rotation_trigger = "%s"
+						}
+					  }
+					}
+				`, keyFile, agePubkey, trigger)
+	}
+
+	var generated []map[string]string
+	checkGenerated := resource.TestCheckResourceAttrWith("sopsage_generated_secret.test", "encrypted", func(encrypted string) error {
+		assert.NotContains(t, encrypted, "sops_unencrypted")
+		plaintext, err := SopsDecryptData(encrypted, "yaml", &DecryptionConfig{AgePrivateKey: agePrivkey})
+		if err != nil {
+			return err
+		}
+		branches, err := sopsStore("yaml", nil).LoadPlainFile([]byte(plaintext))
+		if err != nil {
+			return err
+		}
+		secrets := map[string]string{}
+		for _, item := range branches[0] {
+			secrets[item.Key.(string)] = item.Value.(string)
+		}
+		assert.Regexp(t, "^[ab]{12}$", secrets["password"])
+		token, err := hex.DecodeString(secrets["token"])
+		require.NoError(t, err)
+		assert.Len(t, token, 16)
+		generated = append(generated, secrets)
+		return nil
+	})
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config("1"),
+				Check:  checkGenerated,
+			},
+			{
+				Config: config("1"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+			{
+				Config: config("2"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_generated_secret.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: checkGenerated,
+			},
+		},
+	})
+
+	require.Len(t, generated, 2)
+	assert.Equal(t, generated[0]["password"], generated[1]["password"])
+	assert.NotEqual(t, generated[0]["token"], generated[1]["token"])
+}
+
+func TestSopsGeneratedSecretResourceCharsetValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_generated_secret" "test" {
+					  age_public_keys = ["%s"]
+					  secrets = {
+						token = {
+						  type    = "base64"
+						  charset = "ab"
+						}
+					  }
+					}
+				`, agePubkey),
+				ExpectError: regexp.MustCompile("Invalid Secret Settings"),
+			},
+		},
+	})
+}
+
+func TestGenerateSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  generatedSecretModel
+		pattern string
+	}{
+		{
+			name:    "password",
+			secret:  generatedSecretModel{Type: types.StringValue("password"), Length: types.Int64Value(32)},
+			pattern: "^[a-zA-Z0-9]{32}$",
+		},
+		{
+			name:    "hex",
+			secret:  generatedSecretModel{Type: types.StringValue("hex"), Length: types.Int64Value(4)},
+			pattern: "^[0-9a-f]{8}$",
+		},
+		{
+			name:    "base64",
+			secret:  generatedSecretModel{Type: types.StringValue("base64"), Length: types.Int64Value(3)},
+			pattern: "^[a-zA-Z0-9+/]{4}$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret, err := generateSecret(tt.secret)
+			require.NoError(t, err)
+			assert.Regexp(t, tt.pattern, secret)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"filippo.io/age"
+	"github.com/ProtonMail/go-crypto/openpgp"
+	keysource "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/hcvault"
+	"github.com/getsops/sops/v3/keyservice"
+	vault "github.com/hashicorp/vault/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// VaultConfig holds the credentials used to reach Vault transit keys.
+type VaultConfig struct {
+	// Token authenticates to Vault. When empty, VAULT_TOKEN and ~/.vault-token are used, like the sops CLI does.
+	Token string
+}
+
+// KeyServiceConfig configures the SOPS key services encrypting and decrypting data keys.
+type KeyServiceConfig struct {
+	// Vault holds the credentials used for Vault transit keys.
+	Vault *VaultConfig
+	// Remote are clients of sops keyservice servers, tried after the local key service.
+	Remote []keyservice.KeyServiceClient
+	// DisableLocal leaves every data key to the remote key services.
+	DisableLocal bool
+}
+
+// DialKeyService returns a connection to the sops keyservice server listening at uri, such as "unix:///run/sops.sock"
+// or "tcp://localhost:5000", like the --keyservice flag of the sops CLI. The caller closes the connection.
+func DialKeyService(uri string) (*grpc.ClientConn, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	var target string
+	switch u.Scheme {
+	case "unix":
+		target = uri
+	case "tcp":
+		target = u.Host
+	default:
+		return nil, fmt.Errorf("unsupported key service address %q, expected unix:// or tcp://", uri)
+	}
+	return grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+// keyServiceClients returns the key services SOPS tries in order: the local one, unless disabled, then the remote ones.
+func keyServiceClients(config *KeyServiceConfig, local *sopsKeyService) []keyservice.KeyServiceClient {
+	if config == nil {
+		return []keyservice.KeyServiceClient{keyservice.NewCustomLocalClient(local)}
+	}
+	var clients []keyservice.KeyServiceClient
+	if !config.DisableLocal {
+		clients = append(clients, keyservice.NewCustomLocalClient(local))
+	}
+	return append(clients, config.Remote...)
+}
+
+// VaultTransitKey is a Vault transit key able to encrypt SOPS data keys.
+type VaultTransitKey struct {
+	Address    string
+	EnginePath string
+	KeyName    string
+}
+
+// VaultAppRoleLogin logs in to Vault with AppRole and returns the client token.
+func VaultAppRoleLogin(ctx context.Context, address string, mountPath string, roleID string, secretID string) (string, error) {
+	config := vault.DefaultConfig()
+	if address != "" {
+		config.Address = address
+	}
+	client, err := vault.NewClient(config)
+	if err != nil {
+		return "", fmt.Errorf("cannot create Vault client: %w", err)
+	}
+	client.ClearToken()
+
+	secret, err := client.Logical().WriteWithContext(ctx, "auth/"+mountPath+"/login", map[string]any{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return "", fmt.Errorf("no client token in the AppRole login response")
+	}
+	return secret.Auth.ClientToken, nil
+}
+
+// sopsKeyService is the local SOPS key service of the provider. It extends the one of SOPS with armored PGP public
+// keys and the provider Vault credentials; every other request is handled by the embedded server.
+type sopsKeyService struct {
+	keyservice.Server
+	pgpEntities   map[string]*openpgp.Entity
+	vault         *VaultConfig
+	ageIdentities []age.Identity
+}
+
+func newSopsKeyService(config *KeyServiceConfig) *sopsKeyService {
+	service := &sopsKeyService{pgpEntities: map[string]*openpgp.Entity{}}
+	if config != nil {
+		service.vault = config.Vault
+	}
+	return service
+}
+
+// vaultMasterKey returns the SOPS master key of a Vault transit key, authenticated with the provider credentials.
+func (s *sopsKeyService) vaultMasterKey(key *keyservice.VaultKey) *hcvault.MasterKey {
+	masterKey := &hcvault.MasterKey{
+		VaultAddress: key.VaultAddress,
+		EnginePath:   key.EnginePath,
+		KeyName:      key.KeyName,
+	}
+	hcvault.Token(s.vault.Token).ApplyToMasterKey(masterKey)
+	return masterKey
+}
+
+// Encrypt encrypts a data key.
+func (s *sopsKeyService) Encrypt(ctx context.Context, req *keyservice.EncryptRequest) (*keyservice.EncryptResponse, error) {
+	switch k := req.Key.KeyType.(type) {
+	case *keyservice.Key_PgpKey:
+		if entity, ok := s.pgpEntities[k.PgpKey.Fingerprint]; ok {
+			ciphertext, err := pgpEncrypt(entity, req.Plaintext)
+			if err != nil {
+				return nil, err
+			}
+			return &keyservice.EncryptResponse{Ciphertext: ciphertext}, nil
+		}
+	case *keyservice.Key_VaultKey:
+		if s.vault != nil {
+			masterKey := s.vaultMasterKey(k.VaultKey)
+			if err := masterKey.EncryptContext(ctx, req.Plaintext); err != nil {
+				return nil, err
+			}
+			return &keyservice.EncryptResponse{Ciphertext: masterKey.EncryptedDataKey()}, nil
+		}
+	}
+	return s.Server.Encrypt(ctx, req)
+}
+
+// Decrypt decrypts a data key.
+func (s *sopsKeyService) Decrypt(ctx context.Context, req *keyservice.DecryptRequest) (*keyservice.DecryptResponse, error) {
+	switch k := req.Key.KeyType.(type) {
+	case *keyservice.Key_AgeKey:
+		// Age keys are only decrypted with the given identities, sops would otherwise read them from the environment.
+		if len(s.ageIdentities) == 0 {
+			return nil, fmt.Errorf("no age identities to decrypt the data key for %s", k.AgeKey.Recipient)
+		}
+		masterKey := &keysource.MasterKey{Recipient: k.AgeKey.Recipient, EncryptedKey: string(req.Ciphertext)}
+		keysource.ParsedIdentities(s.ageIdentities).ApplyToMasterKey(masterKey)
+		plaintext, err := masterKey.Decrypt()
+		if err != nil {
+			return nil, err
+		}
+		return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+	case *keyservice.Key_VaultKey:
+		if s.vault != nil {
+			masterKey := s.vaultMasterKey(k.VaultKey)
+			masterKey.SetEncryptedDataKey(req.Ciphertext)
+			plaintext, err := masterKey.DecryptContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+		}
+	}
+	return s.Server.Decrypt(ctx, req)
+}
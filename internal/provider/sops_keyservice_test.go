@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+const (
+	fakeVaultToken    = "s.sopsage-test-token"
+	fakeVaultRoleID   = "sopsage-role"
+	fakeVaultSecretID = "sopsage-secret"
+)
+
+// newFakeVault starts a stand-in for `vault server -dev` serving AppRole logins and the transit encrypt and decrypt
+// endpoints. Its "ciphertexts" are only base64 encoded, which is enough to exercise the SOPS key service.
+func newFakeVault(t *testing.T) *httptest.Server {
+	t.Helper()
+	reply := func(w http.ResponseWriter, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(body)
+	}
+	fail := func(w http.ResponseWriter, code int, message string) {
+		w.WriteHeader(code)
+		reply(w, map[string]any{"errors": []string{message}})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			fail(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if r.URL.Path == "/v1/auth/approle/login" {
+			if body["role_id"] != fakeVaultRoleID || body["secret_id"] != fakeVaultSecretID {
+				fail(w, http.StatusBadRequest, "invalid role or secret ID")
+				return
+			}
+			reply(w, map[string]any{"auth": map[string]any{"client_token": fakeVaultToken}})
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != fakeVaultToken {
+			fail(w, http.StatusForbidden, "permission denied")
+			return
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/v1/transit/encrypt/"):
+			reply(w, map[string]any{"data": map[string]any{
+				"ciphertext": "vault:v1:" + base64.StdEncoding.EncodeToString([]byte(body["plaintext"])),
+			}})
+		case strings.HasPrefix(r.URL.Path, "/v1/transit/decrypt/"):
+			plaintext, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(body["ciphertext"], "vault:v1:"))
+			if err != nil {
+				fail(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			reply(w, map[string]any{"data": map[string]any{"plaintext": string(plaintext)}})
+		default:
+			fail(w, http.StatusNotFound, "no handler for route")
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSopsEncryptDataWithVaultTransitKeys(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("HOME", t.TempDir())
+	server := newFakeVault(t)
+
+	data := `{"foo": "bar"}`
+	encryptionConfig := DefaultEncryptionConfig()
+	encryptionConfig.VaultTransitKeys = []VaultTransitKey{{Address: server.URL, EnginePath: "transit", KeyName: "sops"}}
+	encryptionConfig.KeyServices = &KeyServiceConfig{Vault: &VaultConfig{Token: fakeVaultToken}}
+	encrypted, err := SopsEncryptDataFromAgeKeys(data, "json", []string{agePubkey}, encryptionConfig)
+	require.NoError(t, err)
+
+	decrypted, err := SopsDecryptData(encrypted, "json", &DecryptionConfig{KeyServices: &KeyServiceConfig{Vault: &VaultConfig{Token: fakeVaultToken}}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, data, decrypted)
+
+	decrypted, err = SopsDecryptData(encrypted, "json", &DecryptionConfig{AgePrivateKey: agePrivkey})
+	assert.NoError(t, err)
+	assert.JSONEq(t, data, decrypted)
+
+	_, err = SopsDecryptData(encrypted, "json", &DecryptionConfig{KeyServices: &KeyServiceConfig{Vault: &VaultConfig{Token: "wrong"}}})
+	assert.Error(t, err)
+
+	encryptionConfig.KeyServices = &KeyServiceConfig{Vault: &VaultConfig{Token: "wrong"}}
+	_, err = SopsEncryptDataFromAgeKeys(data, "json", []string{agePubkey}, encryptionConfig)
+	assert.Error(t, err)
+}
+
+func TestSopsEncryptResourceVaultTransitKeys(t *testing.T) {
+	t.Setenv("VAULT_TOKEN", "")
+	t.Setenv("HOME", t.TempDir())
+	server := newFakeVault(t)
+
+	config := func(secretID string) string {
+		return fmt.Sprintf(`
+					provider "sopsage" {
+					  vault = {
+					    address = "%s"
+					    approle = {
+					      role_id   = "%s"
+					      secret_id = "%s"
+					    }
+					  }
+					}
+
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					  vault_transit_keys = [{
+					    address  = "%s"
+					    key_name = "sops"
+					  }]
+					}`, server.URL, fakeVaultRoleID, secretID, agePubkey, server.URL)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("wrong"),
+				ExpectError: regexp.MustCompile("Vault AppRole Login Failed"),
+			},
+			{
+				Config: config(fakeVaultSecretID),
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					_, err := SopsDecryptData(value, "yaml", &DecryptionConfig{KeyServices: &KeyServiceConfig{Vault: &VaultConfig{Token: fakeVaultToken}}})
+					return err
+				}),
+			},
+		},
+	})
+}
+
+// ageKeyService stands in for a `sops keyservice` running next to an HSM: it holds an age identity the provider
+// process never sees.
+type ageKeyService struct {
+	keyservice.Server
+	identity age.Identity
+	calls    atomic.Int32
+}
+
+func (s *ageKeyService) Encrypt(ctx context.Context, req *keyservice.EncryptRequest) (*keyservice.EncryptResponse, error) {
+	s.calls.Add(1)
+	return s.Server.Encrypt(ctx, req)
+}
+
+func (s *ageKeyService) Decrypt(ctx context.Context, req *keyservice.DecryptRequest) (*keyservice.DecryptResponse, error) {
+	s.calls.Add(1)
+	if _, ok := req.Key.KeyType.(*keyservice.Key_AgeKey); !ok {
+		return s.Server.Decrypt(ctx, req)
+	}
+	r, err := age.Decrypt(armor.NewReader(bytes.NewReader(req.Ciphertext)), s.identity)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &keyservice.DecryptResponse{Plaintext: plaintext}, nil
+}
+
+// startAgeKeyService serves an ageKeyService holding agePrivkey on a unix socket, or a local TCP port, and returns its
+// address.
+func startAgeKeyService(t *testing.T, network string) (*ageKeyService, string) {
+	t.Helper()
+	identity, err := age.ParseX25519Identity(agePrivkey)
+	require.NoError(t, err)
+	service := &ageKeyService{identity: identity}
+
+	address := "127.0.0.1:0"
+	if network == "unix" {
+		address = filepath.Join(t.TempDir(), "keyservice.sock")
+	}
+	listener, err := net.Listen(network, address)
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	keyservice.RegisterKeyServiceServer(server, service)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return service, network + "://" + listener.Addr().String()
+}
+
+func TestSopsRemoteKeyService(t *testing.T) {
+	for _, network := range []string{"unix", "tcp"} {
+		t.Run(network, func(t *testing.T) {
+			testSopsRemoteKeyService(t, network)
+		})
+	}
+
+	_, err := DialKeyService("ftp://localhost:5000")
+	assert.Error(t, err)
+}
+
+func testSopsRemoteKeyService(t *testing.T, network string) {
+	service, address := startAgeKeyService(t, network)
+	conn, err := DialKeyService(address)
+	require.NoError(t, err)
+	defer conn.Close()
+	keyServices := &KeyServiceConfig{Remote: []keyservice.KeyServiceClient{keyservice.NewKeyServiceClient(conn)}, DisableLocal: true}
+
+	data := `{"foo": "bar"}`
+	encryptionConfig := DefaultEncryptionConfig()
+	encryptionConfig.KeyServices = keyServices
+	encrypted, err := SopsEncryptDataFromAgeKeys(data, "json", []string{agePubkey}, encryptionConfig)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), service.calls.Load())
+
+	decrypted, err := SopsDecryptData(encrypted, "json", &DecryptionConfig{KeyServices: keyServices})
+	assert.NoError(t, err)
+	assert.JSONEq(t, data, decrypted)
+	assert.Equal(t, int32(2), service.calls.Load())
+
+	_, err = SopsDecryptData(encrypted, "json", &DecryptionConfig{})
+	assert.Error(t, err, "the local key service has no identity")
+}
+
+func TestProviderKeyServiceReconfigure(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	_, address := startAgeKeyService(t, "unix")
+	ctx := context.Background()
+	p := New("test")().(*SopsAgeProvider)
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	require.False(t, state.SetAttribute(ctx, path.Root("keyservices"), []string{address}).HasError())
+	config := tfsdk.Config{Schema: state.Schema, Raw: state.Raw}
+
+	var resp provider.ConfigureResponse
+	p.Configure(ctx, provider.ConfigureRequest{Config: config}, &resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	require.Len(t, p.keyServiceConns, 1)
+	previous := p.keyServiceConns[0]
+
+	p.Configure(ctx, provider.ConfigureRequest{Config: config}, &resp)
+	require.False(t, resp.Diagnostics.HasError(), resp.Diagnostics)
+	require.Len(t, p.keyServiceConns, 1)
+	assert.Equal(t, connectivity.Shutdown, previous.GetState())
+	assert.NotEqual(t, connectivity.Shutdown, p.keyServiceConns[0].GetState())
+}
+
+func TestProviderRemoteKeyService(t *testing.T) {
+	service, address := startAgeKeyService(t, "unix")
+
+	config := func(address string) string {
+		return fmt.Sprintf(`
+					provider "sopsage" {
+					  keyservices             = ["%s"]
+					  enable_local_keyservice = false
+					}
+
+					resource "sopsage_encrypted_data" "test" {
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					}`, address, agePubkey)
+	}
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config:      config("localhost:5000"),
+				ExpectError: regexp.MustCompile("Invalid Key Service Address"),
+			},
+			{
+				Config: config(address),
+				Check: func(_ *terraform.State) error {
+					if service.calls.Load() == 0 {
+						return fmt.Errorf("the remote key service was not used")
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
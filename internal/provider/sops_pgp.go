@@ -0,0 +1,62 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/getsops/sops/v3/keys"
+	"github.com/getsops/sops/v3/pgp"
+)
+
+// pgpFingerprint returns the fingerprint of an entity, in the format SOPS records in its metadata.
+func pgpFingerprint(entity *openpgp.Entity) string {
+	return strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+}
+
+// addPgpPublicKeys parses armored PGP public keys, so that the key service encrypts for them without a GnuPG keyring,
+// and returns the matching SOPS master keys.
+func (s *sopsKeyService) addPgpPublicKeys(pgpPublicKeys []string) ([]keys.MasterKey, error) {
+	var masterKeys []keys.MasterKey
+	for _, armored := range pgpPublicKeys {
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse PGP public key: %w", err)
+		}
+		for _, entity := range entities {
+			fingerprint := pgpFingerprint(entity)
+			if _, ok := s.pgpEntities[fingerprint]; ok {
+				continue
+			}
+			s.pgpEntities[fingerprint] = entity
+			masterKeys = append(masterKeys, pgp.NewMasterKeyFromFingerprint(fingerprint))
+		}
+	}
+	return masterKeys, nil
+}
+
+// pgpEncrypt encrypts a data key for entity as an armored PGP message, like the sops CLI does.
+func pgpEncrypt(entity *openpgp.Entity, dataKey []byte) ([]byte, error) {
+	var out bytes.Buffer
+	armorWriter, err := armor.Encode(&out, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, err
+	}
+	w, err := openpgp.Encrypt(armorWriter, []*openpgp.Entity{entity}, nil, &openpgp.FileHints{IsBinary: true}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(dataKey); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,110 @@
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/getsops/sops/v3/pgp"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPgpKey generates a PGP key and returns it along with its armored public key.
+func newTestPgpKey(t *testing.T) (*openpgp.Entity, string) {
+	t.Helper()
+	entity, err := openpgp.NewEntity("sopsage", "test", "sopsage@example.com", &packet.Config{Algorithm: packet.PubKeyAlgoEdDSA})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	w, err := armor.Encode(&out, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	return entity, out.String()
+}
+
+// pgpDataKey decrypts the data key a SOPS document holds for entity.
+func pgpDataKey(t *testing.T, encrypted string, format string, entity *openpgp.Entity) []byte {
+	t.Helper()
+	metadata, err := SopsLoadMetadata(encrypted, format)
+	require.NoError(t, err)
+
+	fingerprint := pgpFingerprint(entity)
+	for _, group := range metadata.KeyGroups {
+		for _, key := range group {
+			pgpKey, ok := key.(*pgp.MasterKey)
+			if !ok || pgpKey.Fingerprint != fingerprint {
+				continue
+			}
+			block, err := armor.Decode(strings.NewReader(pgpKey.EncryptedKey))
+			require.NoError(t, err)
+			message, err := openpgp.ReadMessage(block.Body, openpgp.EntityList{entity}, nil, nil)
+			require.NoError(t, err)
+			dataKey, err := io.ReadAll(message.UnverifiedBody)
+			require.NoError(t, err)
+			return dataKey
+		}
+	}
+	t.Fatalf("no PGP key with fingerprint %s in the metadata", fingerprint)
+	return nil
+}
+
+func TestSopsEncryptDataWithPgpPublicKeys(t *testing.T) {
+	entity, publicKey := newTestPgpKey(t)
+	otherEntity, otherPublicKey := newTestPgpKey(t)
+
+	encryptionConfig := DefaultEncryptionConfig()
+	encryptionConfig.PgpPublicKeys = []string{publicKey, otherPublicKey, publicKey}
+	encrypted, err := SopsEncryptDataFromAgeKeys("foo: bar\n", "yaml", []string{agePubkey}, encryptionConfig)
+	require.NoError(t, err)
+
+	metadata, err := SopsLoadMetadata(encrypted, "yaml")
+	require.NoError(t, err)
+	assert.Len(t, metadata.KeyGroups[0], 3, "one age key and two distinct PGP keys")
+
+	dataKey, err := SopsDataKeyFromAgeKey(encrypted, "yaml", agePrivkey)
+	require.NoError(t, err)
+	assert.Equal(t, dataKey, pgpDataKey(t, encrypted, "yaml", entity))
+	assert.Equal(t, dataKey, pgpDataKey(t, encrypted, "yaml", otherEntity))
+
+	encryptionConfig.PgpPublicKeys = []string{"not a key"}
+	_, err = SopsEncryptDataFromAgeKeys("foo: bar\n", "yaml", []string{agePubkey}, encryptionConfig)
+	assert.Error(t, err)
+}
+
+func TestSopsEncryptResourcePgpPublicKeys(t *testing.T) {
+	entity, publicKey := newTestPgpKey(t)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "test" {
+					  format = "json"
+					  content = jsonencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					  pgp_public_keys = [%q]
+					}`, agePubkey, publicKey),
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					dataKey, err := SopsDataKeyFromAgeKey(value, "json", agePrivkey)
+					if err != nil {
+						return err
+					}
+					if !bytes.Equal(dataKey, pgpDataKey(t, value, "json", entity)) {
+						return fmt.Errorf("the PGP and age data keys differ")
+					}
+					return nil
+				}),
+			},
+		},
+	})
+}
@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ resource.Resource               = &sopsReencryptResource{}
+	_ resource.ResourceWithConfigure  = &sopsReencryptResource{}
+	_ resource.ResourceWithModifyPlan = &sopsReencryptResource{}
+)
+
+// NewSopsReencryptResource is a helper function to simplify the provider implementation.
+func NewSopsReencryptResource() resource.Resource {
+	return &sopsReencryptResource{}
+}
+
+// sopsReencryptResource is the resource implementation.
+type sopsReencryptResource struct {
+	providerData *sopsAgeProviderData
+}
+
+// sopsReencryptResourceModel maps the resource schema data.
+type sopsReencryptResourceModel struct {
+	ID              types.String `tfsdk:"id"`
+	SourceEncrypted types.String `tfsdk:"source_encrypted"`
+	Format          types.String `tfsdk:"format"`
+	AgePrivateKeys  types.List   `tfsdk:"age_private_keys"`
+	AgePublicKeys   types.List   `tfsdk:"age_public_keys"`
+	RotateDataKey   types.Bool   `tfsdk:"rotate_data_key"`
+	Encrypted       types.String `tfsdk:"encrypted"`
+}
+
+// Configure adds the provider data to the resource.
+func (r *sopsReencryptResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	providerData, diags := providerDataFrom(req.ProviderData)
+	resp.Diagnostics.Append(diags...)
+	r.providerData = providerData
+}
+
+// Metadata returns the resource type name.
+func (r *sopsReencryptResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_reencrypted_data"
+}
+
+// Schema defines the schema for the resource.
+func (r *sopsReencryptResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Re-encrypts an existing SOPS document for a new set of age recipients. " +
+			"The decrypted content is only kept in memory, and the encryption rules of the source document are preserved.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier for the resource.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source_encrypted": schema.StringAttribute{
+				Description: "The SOPS document to re-encrypt.",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"format": schema.StringAttribute{
+				Description: "The format of the source document (json, yaml, etc.).",
+				Required:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"age_private_keys": schema.ListAttribute{
+				Description: "List of age private keys able to decrypt the source document, defaults to the identities of the provider age key files.",
+				Optional:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"age_public_keys": schema.ListAttribute{
+				Description: "List of age public keys to encrypt with.",
+				Required:    true,
+				ElementType: types.StringType,
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.RequiresReplace(),
+				},
+			},
+			"rotate_data_key": schema.BoolAttribute{
+				Description: "Generate a new data key instead of reusing the one of the source document. Defaults to true " +
+					"when age_public_keys drops any recipient of the source document, and to false otherwise. Setting it to " +
+					"false while removing recipients lets anyone who decrypted the source document read the new one too.",
+				Optional:      true,
+				Computed:      true,
+				PlanModifiers: []planmodifier.Bool{boolplanmodifier.RequiresReplace()},
+			},
+			"encrypted": schema.StringAttribute{
+				Description: "The re-encrypted content in SOPS format.",
+				Computed:    true,
+				Sensitive:   false,
+			},
+		},
+	}
+}
+
+// ModifyPlan rotates the data key by default when recipients of the source document are removed.
+func (r *sopsReencryptResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// Nothing to plan on destruction
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config, plan sopsReencryptResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !config.RotateDataKey.IsNull() {
+		return
+	}
+
+	// Keep the value decided on creation as long as the resource is not replaced.
+	if !req.State.Raw.IsNull() {
+		var state sopsReencryptResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if plan.SourceEncrypted.Equal(state.SourceEncrypted) && plan.Format.Equal(state.Format) &&
+			plan.AgePublicKeys.Equal(state.AgePublicKeys) && plan.AgePrivateKeys.Equal(state.AgePrivateKeys) {
+			plan.RotateDataKey = state.RotateDataKey
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+			return
+		}
+	}
+
+	if plan.SourceEncrypted.IsUnknown() || plan.Format.IsUnknown() || plan.AgePublicKeys.IsUnknown() {
+		plan.RotateDataKey = types.BoolUnknown()
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+		return
+	}
+	var agePublicKeys []string
+	resp.Diagnostics.Append(plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	removed, err := SopsRemovedRecipients(plan.SourceEncrypted.ValueString(), plan.Format.ValueString(), agePublicKeys)
+	// A source document that cannot be read fails on apply, rotate in the meantime.
+	plan.RotateDataKey = types.BoolValue(err != nil || len(removed) > 0)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, plan)...)
+}
+
+// Create re-encrypts the source document.
+func (r *sopsReencryptResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	// Retrieve values from plan
+	var plan sopsReencryptResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sourceEncrypted := plan.SourceEncrypted.ValueString()
+	format := plan.Format.ValueString()
+
+	// Get the age keys
+	var agePrivateKeys []string
+	diags = plan.AgePrivateKeys.ElementsAs(ctx, &agePrivateKeys, false)
+	resp.Diagnostics.Append(diags...)
+	var agePublicKeys []string
+	diags = plan.AgePublicKeys.ElementsAs(ctx, &agePublicKeys, false)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Re-encrypt the content
+	encrypted, err := SopsReencryptDataFromAgeKeys(
+		sourceEncrypted,
+		format,
+		&DecryptionConfig{
+			AgePrivateKey: r.providerData.agePrivateKey(agePrivateKeys),
+			KeyServices:   r.providerData.keyServiceConfig(),
+		},
+		agePublicKeys,
+		plan.RotateDataKey.ValueBool(),
+		r.providerData.storesConfig(),
+	)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Re-encrypting Content",
+			fmt.Sprintf("Could not re-encrypt content: %s", err),
+		)
+		return
+	}
+
+	// Generate a unique ID based on the source document and keys
+	h := sha256.New()
+	h.Write([]byte(sourceEncrypted))
+	for _, key := range agePublicKeys {
+		h.Write([]byte(key))
+	}
+	id := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	// Set resource ID
+	plan.ID = types.StringValue(id)
+	// Set encrypted content
+	plan.Encrypted = types.StringValue(encrypted)
+
+	// Set state to computed values
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (r *sopsReencryptResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	// Get current state
+	var state sopsReencryptResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Set refreshed state
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+}
+
+// Update updates the resource and sets the updated Terraform state on success.
+func (r *sopsReencryptResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.AddError(
+		"Resource Does Not Support Update",
+		"The sopsage_reencrypted_data resource does not support updates. All changes require resource replacement.",
+	)
+}
+
+// Delete deletes the resource and removes the Terraform state on success.
+func (r *sopsReencryptResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	// Re-encrypted content doesn't have any external resources to clean up
+	// The state will be removed by Terraform automatically
+}
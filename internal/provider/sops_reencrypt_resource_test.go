@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestSopsReencryptResource(t *testing.T) {
+	config := fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "source" {
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					}
+
+					resource "sopsage_reencrypted_data" "test" {
+					  format = "yaml"
+					  source_encrypted = sopsage_encrypted_data.source.encrypted
+					  age_private_keys = ["%s"]
+					  age_public_keys = ["%s"]
+					}
+				`, agePubkey, agePrivkey, otherAgePubkey)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				// The source recipient was removed.
+				Check: resource.TestCheckResourceAttr("sopsage_reencrypted_data.test", "rotate_data_key", "true"),
+			},
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestSopsReencryptResourceAddedRecipient(t *testing.T) {
+	config := fmt.Sprintf(`
+					resource "sopsage_encrypted_data" "source" {
+					  format = "yaml"
+					  content = yamlencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					}
+
+					resource "sopsage_reencrypted_data" "test" {
+					  format = "yaml"
+					  source_encrypted = sopsage_encrypted_data.source.encrypted
+					  age_private_keys = ["%s"]
+					  age_public_keys = ["%s", "%s"]
+					}
+				`, agePubkey, agePrivkey, agePubkey, otherAgePubkey)
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check:  resource.TestCheckResourceAttr("sopsage_reencrypted_data.test", "rotate_data_key", "false"),
+			},
+			{
+				Config: config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"github.com/getsops/sops/v3/config"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	yamlIndentDescription       = "Number of spaces the YAML output is indented with, defaults to 4 like the sops CLI."
+	jsonIndentDescription       = "Number of spaces the JSON output is indented with, defaults to a tab like the sops CLI."
+	jsonBinaryIndentDescription = "Number of spaces the JSON wrapping binary content is indented with, defaults to a tab like the sops CLI."
+)
+
+// sopsStoreFormattingModel maps the output formatting of the SOPS stores, null values keep the formatting they
+// override.
+type sopsStoreFormattingModel struct {
+	YAMLIndent       types.Int64 `tfsdk:"yaml_indent"`
+	JSONIndent       types.Int64 `tfsdk:"json_indent"`
+	JSONBinaryIndent types.Int64 `tfsdk:"json_binary_indent"`
+}
+
+// StoresConfig returns stores, or the stores of the sops CLI when nil, with the formatting of the model applied.
+func (m sopsStoreFormattingModel) StoresConfig(stores *config.StoresConfig) *config.StoresConfig {
+	result := config.NewStoresConfig()
+	if stores != nil {
+		*result = *stores
+	}
+	if !m.YAMLIndent.IsNull() && !m.YAMLIndent.IsUnknown() {
+		result.YAML.Indent = int(m.YAMLIndent.ValueInt64())
+	}
+	if !m.JSONIndent.IsNull() && !m.JSONIndent.IsUnknown() {
+		result.JSON.Indent = int(m.JSONIndent.ValueInt64())
+	}
+	if !m.JSONBinaryIndent.IsNull() && !m.JSONBinaryIndent.IsUnknown() {
+		result.JSONBinary.Indent = int(m.JSONBinaryIndent.ValueInt64())
+	}
+	return result
+}
+
+// Equal reports whether both models set the same formatting.
+func (m sopsStoreFormattingModel) Equal(o sopsStoreFormattingModel) bool {
+	return m.YAMLIndent.Equal(o.YAMLIndent) && m.JSONIndent.Equal(o.JSONIndent) && m.JSONBinaryIndent.Equal(o.JSONBinaryIndent)
+}
+
+// sopsStoreFormattingProviderAttributes defines the provider schema of the output formatting.
+func sopsStoreFormattingProviderAttributes() map[string]providerschema.Attribute {
+	const reformatDescriptionSuffix = " Changes reformat the content of sopsage_encrypted_data in place. The other resources encrypting " +
+		"content only take this setting, with no override, and keep their encrypted content until it is encrypted again."
+	return map[string]providerschema.Attribute{
+		"yaml_indent": providerschema.Int64Attribute{
+			Description: yamlIndentDescription + reformatDescriptionSuffix,
+			Optional:    true,
+			Validators:  []validator.Int64{int64validator.AtLeast(1)},
+		},
+		"json_indent": providerschema.Int64Attribute{
+			Description: jsonIndentDescription + reformatDescriptionSuffix,
+			Optional:    true,
+			Validators:  []validator.Int64{int64validator.AtLeast(0)},
+		},
+		"json_binary_indent": providerschema.Int64Attribute{
+			Description: jsonBinaryIndentDescription + reformatDescriptionSuffix,
+			Optional:    true,
+			Validators:  []validator.Int64{int64validator.AtLeast(0)},
+		},
+	}
+}
+
+// sopsStoreFormattingAttributes defines the resource schema of the output formatting, overriding the provider.
+func sopsStoreFormattingAttributes() map[string]schema.Attribute {
+	const overrideDescriptionSuffix = " Overrides the setting of the provider, changes reformat the encrypted content in place."
+	return map[string]schema.Attribute{
+		"yaml_indent": schema.Int64Attribute{
+			Description: yamlIndentDescription + overrideDescriptionSuffix,
+			Optional:    true,
+			Validators:  []validator.Int64{int64validator.AtLeast(1)},
+		},
+		"json_indent": schema.Int64Attribute{
+			Description: jsonIndentDescription + overrideDescriptionSuffix,
+			Optional:    true,
+			Validators:  []validator.Int64{int64validator.AtLeast(0)},
+		},
+		"json_binary_indent": schema.Int64Attribute{
+			Description: jsonBinaryIndentDescription + overrideDescriptionSuffix,
+			Optional:    true,
+			Validators:  []validator.Int64{int64validator.AtLeast(0)},
+		},
+	}
+}
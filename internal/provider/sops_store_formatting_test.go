@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getsops/sops/v3/config"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/compare"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSopsStoreFormattingStoresConfig(t *testing.T) {
+	assert.Equal(t, config.NewStoresConfig(), sopsStoreFormattingModel{}.StoresConfig(nil))
+
+	provider := sopsStoreFormattingModel{YAMLIndent: types.Int64Value(2), JSONIndent: types.Int64Value(4)}.StoresConfig(nil)
+	stores := sopsStoreFormattingModel{JSONIndent: types.Int64Value(2)}.StoresConfig(provider)
+	assert.Equal(t, 2, stores.YAML.Indent)
+	assert.Equal(t, 2, stores.JSON.Indent)
+	assert.Equal(t, -1, stores.JSONBinary.Indent)
+	assert.Equal(t, 4, provider.JSON.Indent)
+}
+
+func TestSopsReformatData(t *testing.T) {
+	encrypted, err := SopsEncryptDataFromAgeKeys("a:\n    b: c\n", "yaml", []string{agePubkey}, nil)
+	require.NoError(t, err)
+	require.Contains(t, encrypted, "\n    b: ENC[")
+
+	stores := config.NewStoresConfig()
+	stores.YAML.Indent = 2
+	reformatted, err := SopsReformatData(encrypted, "yaml", stores)
+	require.NoError(t, err)
+	assert.Contains(t, reformatted, "\n  b: ENC[")
+
+	decrypted, err := SopsDecryptDataFromAgeKey(reformatted, "yaml", agePrivkey)
+	require.NoError(t, err)
+	assert.Equal(t, "a:\n    b: c\n", decrypted)
+}
+
+func TestSopsEncryptResourceStoreFormatting(t *testing.T) {
+	config := func(providerIndent int, jsonIndent string) string {
+		return fmt.Sprintf(`
+					provider "sopsage" {
+					  json_indent = %d
+					}
+
+					resource "sopsage_encrypted_data" "test" {
+					  format = "json"
+					  content = jsonencode({foo = "bar"})
+					  age_public_keys = ["%s"]
+					  %s
+					}
+				`, providerIndent, agePubkey, jsonIndent)
+	}
+	sameRotatedAt := statecheck.CompareValue(compare.ValuesSame())
+
+	resource.Test(t, resource.TestCase{
+		IsUnitTest:               true,
+		ProtoV6ProviderFactories: testProtoV6ProviderFactories,
+		Steps: []resource.TestStep{
+			{
+				Config: config(4, ""),
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					if !strings.HasPrefix(value, "{\n    \"foo\": ") {
+						return fmt.Errorf("encrypted content is not indented with 4 spaces:\n%s", value)
+					}
+					return nil
+				}),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameRotatedAt.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("rotated_at")),
+				},
+			},
+			{
+				Config: config(4, "json_indent = 1"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("sopsage_encrypted_data.test", tfjsonpath.New("encrypted")),
+					},
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					if !strings.HasPrefix(value, "{\n \"foo\": ") {
+						return fmt.Errorf("encrypted content is not indented with 1 space:\n%s", value)
+					}
+					decrypted, err := SopsDecryptDataFromAgeKey(value, "json", agePrivkey)
+					if err != nil {
+						return err
+					}
+					if decrypted != "{\n\t\"foo\": \"bar\"\n}\n" {
+						return fmt.Errorf("unexpected decrypted content:\n%s", decrypted)
+					}
+					return nil
+				}),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameRotatedAt.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("rotated_at")),
+				},
+			},
+			{
+				// Changing the provider formatting reformats the content too.
+				Config: config(2, ""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("sopsage_encrypted_data.test", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.TestCheckResourceAttrWith("sopsage_encrypted_data.test", "encrypted", func(value string) error {
+					if !strings.HasPrefix(value, "{\n  \"foo\": ") {
+						return fmt.Errorf("encrypted content is not indented with 2 spaces:\n%s", value)
+					}
+					return nil
+				}),
+				ConfigStateChecks: []statecheck.StateCheck{
+					sameRotatedAt.AddStateValue("sopsage_encrypted_data.test", tfjsonpath.New("rotated_at")),
+				},
+			},
+			{
+				Config: config(2, ""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectEmptyPlan(),
+					},
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// vaultTransitKeyModel maps a Vault transit key of the resources producing SOPS documents.
+type vaultTransitKeyModel struct {
+	Address    types.String `tfsdk:"address"`
+	EnginePath types.String `tfsdk:"engine_path"`
+	KeyName    types.String `tfsdk:"key_name"`
+}
+
+// vaultTransitKeyAttributes defines the schema of a Vault transit key.
+func vaultTransitKeyAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"address": schema.StringAttribute{
+			Description: "Address of the Vault server, such as \"https://vault.example.com:8200\".",
+			Required:    true,
+		},
+		"engine_path": schema.StringAttribute{
+			Description: "Mount path of the transit secrets engine, defaults to \"transit\".",
+			Optional:    true,
+		},
+		"key_name": schema.StringAttribute{
+			Description: "Name of the transit key.",
+			Required:    true,
+		},
+	}
+}
+
+// vaultTransitKeys returns the Vault transit keys matching the models.
+func vaultTransitKeys(models []vaultTransitKeyModel) []VaultTransitKey {
+	keys := make([]VaultTransitKey, 0, len(models))
+	for _, m := range models {
+		enginePath := m.EnginePath.ValueString()
+		if enginePath == "" {
+			enginePath = "transit"
+		}
+		keys = append(keys, VaultTransitKey{
+			Address:    m.Address.ValueString(),
+			EnginePath: enginePath,
+			KeyName:    m.KeyName.ValueString(),
+		})
+	}
+	return keys
+}